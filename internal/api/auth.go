@@ -0,0 +1,67 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+)
+
+// Token scopes: api tokens are sent as bearer tokens to the API, publish tokens are the secret in the
+// url of a user's published feed and give access to nothing else
+const (
+	ScopeAPI     = "api"
+	ScopePublish = "publish"
+)
+
+// GenerateToken returns a new random API token and the hash to store in the database.
+// Only the hash is persisted, the token itself is shown once to the user.
+func GenerateToken() (token string, hash string, err error) {
+	bytes := make([]byte, 32)
+
+	_, err = rand.Read(bytes)
+	if err != nil {
+		return "", "", fmt.Errorf("err generating token: %w", err)
+	}
+
+	token = hex.EncodeToString(bytes)
+
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// requireUser resolves the user from the "Authorization: Bearer <token>" header
+func (s *Server) requireUser(next func(http.ResponseWriter, *http.Request, database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || token == "" {
+			respondWithError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		user, err := s.db.FindUserByAPIToken(
+			r.Context(),
+			database.FindUserByAPITokenParams{TokenHash: HashToken(token), Scope: ScopeAPI},
+		)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusUnauthorized, "invalid token")
+				return
+			}
+			s.internalError(w, fmt.Errorf("err finding user by token: %w", err))
+			return
+		}
+
+		next(w, r, user)
+	}
+}
@@ -0,0 +1,394 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type userResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type feedResponse struct {
+	ID            int32      `json:"id"`
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
+
+type followResponse struct {
+	FeedID      int32   `json:"feed_id"`
+	Name        string  `json:"name"`
+	URL         string  `json:"url"`
+	Folder      *string `json:"folder"`
+	UnreadCount int64   `json:"unread_count"`
+}
+
+type postResponse struct {
+	ID          int32     `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	PublishedAt time.Time `json:"published_at"`
+	FeedID      int32     `json:"feed_id"`
+	FeedName    string    `json:"feed_name"`
+}
+
+type postsPage struct {
+	Posts      []postResponse `json:"posts"`
+	NextOffset *int           `json:"next_offset"`
+}
+
+func toUserResponse(user database.User) userResponse {
+	return userResponse{
+		ID:        user.ID,
+		Name:      user.Name,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+func toFeedResponse(feed database.Feed) feedResponse {
+	resp := feedResponse{
+		ID:        feed.ID,
+		Name:      feed.Name,
+		URL:       feed.Url,
+		CreatedAt: feed.CreatedAt,
+	}
+
+	if feed.LastFetchedAt.Valid {
+		resp.LastFetchedAt = &feed.LastFetchedAt.Time
+	}
+
+	return resp
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondWithJSON(w, http.StatusOK, toUserResponse(user))
+}
+
+func (s *Server) handleGetUsers(w http.ResponseWriter, r *http.Request, user database.User) {
+	users, err := s.db.GetAllUsers(r.Context())
+	if err != nil {
+		s.internalError(w, fmt.Errorf("err getting all users: %w", err))
+		return
+	}
+
+	resp := make([]userResponse, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, toUserResponse(u))
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleGetFeeds(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := s.db.GetAllFeeds(r.Context())
+	if err != nil {
+		s.internalError(w, fmt.Errorf("err getting all feeds: %w", err))
+		return
+	}
+
+	resp := make([]feedResponse, 0, len(feeds))
+	for _, feed := range feeds {
+		resp = append(resp, toFeedResponse(feed))
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleCreateFeed adds a new feed and follows it, like the addfeed command
+func (s *Server) handleCreateFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	var body struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil || body.Name == "" || body.URL == "" {
+		respondWithError(w, http.StatusBadRequest, "body must be a json object with name and url")
+		return
+	}
+
+	feed, err := s.db.CreateFeed(
+		r.Context(),
+		database.CreateFeedParams{
+			Name:      body.Name,
+			Url:       body.URL,
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+		},
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "a feed with this url already exists, follow it instead")
+			return
+		}
+		s.internalError(w, fmt.Errorf("err creating feed: %w", err))
+		return
+	}
+
+	_, err = s.db.CreateFeedFollow(
+		r.Context(),
+		database.CreateFeedFollowParams{
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+		},
+	)
+	if err != nil {
+		s.internalError(w, fmt.Errorf("err creating feed_follow entry: %w", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toFeedResponse(feed))
+}
+
+func (s *Server) handleGetFollows(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := s.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		s.internalError(w, fmt.Errorf("err getting feeds for user: %w", err))
+		return
+	}
+
+	resp := make([]followResponse, 0, len(follows))
+	for _, follow := range follows {
+		f := followResponse{
+			FeedID:      follow.ID,
+			Name:        follow.Name,
+			URL:         follow.Url,
+			UnreadCount: follow.UnreadCount,
+		}
+		if follow.Folder.Valid {
+			f.Folder = &follow.Folder.String
+		}
+		resp = append(resp, f)
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleCreateFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	var body struct {
+		URL string `json:"url"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil || body.URL == "" {
+		respondWithError(w, http.StatusBadRequest, "body must be a json object with url")
+		return
+	}
+
+	feed, err := s.db.FindFeedByURL(r.Context(), body.URL)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "no feed with this url, create it first")
+			return
+		}
+		s.internalError(w, fmt.Errorf("err finding feed: %w", err))
+		return
+	}
+
+	_, err = s.db.CreateFeedFollow(
+		r.Context(),
+		database.CreateFeedFollowParams{
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+		},
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "already following this feed")
+			return
+		}
+		s.internalError(w, fmt.Errorf("err creating feed_follow entry: %w", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toFeedResponse(feed))
+}
+
+func (s *Server) handleDeleteFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedID, err := strconv.Atoi(r.PathValue("feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "feed id must be a number")
+		return
+	}
+
+	err = s.db.DeleteFeedFollowsEntry(
+		r.Context(),
+		database.DeleteFeedFollowsEntryParams{
+			UserID: user.ID,
+			FeedID: int32(feedID),
+		},
+	)
+	if err != nil {
+		s.internalError(w, fmt.Errorf("err deleting feed_follow entry: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetPosts supports ?limit, ?offset, ?unread=true, ?feed=<url>, ?folder, ?author, ?order=asc|desc,
+// ?since and ?until (RFC3339 or 2006-01-02, an until date includes its whole day)
+func (s *Server) handleGetPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	query := r.URL.Query()
+
+	arg := database.GetPostsForUserParams{
+		UserID:     user.ID,
+		UnreadOnly: query.Get("unread") == "true",
+		FeedUrl:    sql.NullString{String: query.Get("feed"), Valid: query.Get("feed") != ""},
+		Folder:     sql.NullString{String: query.Get("folder"), Valid: query.Get("folder") != ""},
+		Author:     sql.NullString{String: query.Get("author"), Valid: query.Get("author") != ""},
+		Limit:      defaultPageSize,
+	}
+
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		arg.OldestFirst = true
+	default:
+		respondWithError(w, http.StatusBadRequest, "order must be asc or desc")
+		return
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be a number between 1 and %d", maxPageSize))
+			return
+		}
+		arg.Limit = int32(limit)
+	}
+
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			respondWithError(w, http.StatusBadRequest, "offset must be a positive number")
+			return
+		}
+		arg.Offset = int32(offset)
+	}
+
+	for _, filter := range []struct {
+		name string
+		dest *sql.NullTime
+	}{{"since", &arg.Since}, {"until", &arg.Until}} {
+		value := query.Get(filter.name)
+		if value == "" {
+			continue
+		}
+
+		t, err := parseTime(value, filter.name == "until")
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, filter.name+" must be a RFC3339 timestamp or a 2006-01-02 date")
+			return
+		}
+		*filter.dest = sql.NullTime{Time: t, Valid: true}
+	}
+
+	posts, err := s.db.GetPostsForUser(r.Context(), arg)
+	if err != nil {
+		s.internalError(w, fmt.Errorf("err getting posts for user: %w", err))
+		return
+	}
+
+	page := postsPage{Posts: make([]postResponse, 0, len(posts))}
+	for _, post := range posts {
+		page.Posts = append(page.Posts, postResponse{
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			Description: post.Description,
+			PublishedAt: post.PublishedAt,
+			FeedID:      post.FeedID,
+			FeedName:    post.FeedName,
+		})
+	}
+
+	// A full page means there might be more
+	if len(posts) == int(arg.Limit) {
+		next := int(arg.Offset) + len(posts)
+		page.NextOffset = &next
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) handleReadPost(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, err := strconv.Atoi(r.PathValue("postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "post id must be a number")
+		return
+	}
+
+	post, err := s.db.FindPostForUser(
+		r.Context(),
+		database.FindPostForUserParams{
+			UserID: user.ID,
+			ID:     int32(postID),
+		},
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "no post with this id in the feeds you follow")
+			return
+		}
+		s.internalError(w, fmt.Errorf("err finding post: %w", err))
+		return
+	}
+
+	err = s.db.MarkPostRead(
+		r.Context(),
+		database.MarkPostReadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+			ReadAt: time.Now().UTC(),
+		},
+	)
+	if err != nil {
+		s.internalError(w, fmt.Errorf("err marking post read: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseTime(value string, until bool) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t.UTC(), nil
+	}
+
+	t, err = time.Parse(time.DateOnly, value)
+	if err == nil && until {
+		// until is exclusive, a date alone covers its whole day
+		return t.AddDate(0, 0, 1), nil
+	}
+	return t, err
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/feedgen"
+)
+
+// handleGetRiver serves the posts of the feeds a user follows as a single feed other readers can subscribe to.
+// Feed readers can't send a bearer token, so the url itself is the secret: it holds a publish token the user
+// created and can revoke. Unknown tokens are a 404, like any url that doesn't exist.
+func (s *Server) handleGetRiver(w http.ResponseWriter, r *http.Request) {
+	format := feedgen.FormatAtom
+
+	if value := r.URL.Query().Get("format"); value != "" {
+		var err error
+		format, err = feedgen.ParseFormat(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	limit := 50
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be a number between 1 and %d", maxPageSize))
+			return
+		}
+	}
+
+	user, err := s.db.FindUserByAPIToken(
+		r.Context(),
+		database.FindUserByAPITokenParams{TokenHash: HashToken(r.PathValue("token")), Scope: ScopePublish},
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "feed not found")
+			return
+		}
+		s.internalError(w, fmt.Errorf("err finding user by publish token: %w", err))
+		return
+	}
+
+	posts, err := s.db.GetPostsForUser(
+		r.Context(),
+		database.GetPostsForUserParams{
+			UserID: user.ID,
+			Limit:  int32(limit),
+		},
+	)
+	if err != nil {
+		s.internalError(w, fmt.Errorf("err getting posts for user: %w", err))
+		return
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	link := scheme + "://" + r.Host + r.URL.RequestURI()
+
+	w.Header().Set("Content-Type", feedgen.ContentTypes[format])
+
+	err = feedgen.Write(w, format, feedgen.NewRiver(user, posts, link))
+	if err != nil {
+		s.internalError(w, fmt.Errorf("err writing feed: %w", err))
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+)
+
+// Server exposes the gator database as a JSON API, every endpoint acts as the user owning the bearer token.
+// The published feeds are the exception, the user is found by the publish token in their url.
+type Server struct {
+	db *database.Queries
+}
+
+func NewServer(db *database.Queries) *Server {
+	return &Server{db: db}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/me", s.requireUser(s.handleGetMe))
+	mux.HandleFunc("GET /api/users", s.requireUser(s.handleGetUsers))
+
+	mux.HandleFunc("GET /api/feeds", s.requireUser(s.handleGetFeeds))
+	mux.HandleFunc("POST /api/feeds", s.requireUser(s.handleCreateFeed))
+
+	mux.HandleFunc("GET /api/follows", s.requireUser(s.handleGetFollows))
+	mux.HandleFunc("POST /api/follows", s.requireUser(s.handleCreateFollow))
+	mux.HandleFunc("DELETE /api/follows/{feedID}", s.requireUser(s.handleDeleteFollow))
+
+	mux.HandleFunc("GET /api/posts", s.requireUser(s.handleGetPosts))
+	mux.HandleFunc("POST /api/posts/{postID}/read", s.requireUser(s.handleReadPost))
+
+	mux.HandleFunc("GET /river/{token}", s.handleGetRiver)
+
+	return mux
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("err marshaling response: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	respondWithJSON(w, code, errorResponse{Error: message})
+}
+
+// internalError logs the real error and hides it from the client, like the CLI does
+func (s *Server) internalError(w http.ResponseWriter, err error) {
+	log.Println(err)
+	respondWithError(w, http.StatusInternalServerError, "internal error, something went wrong")
+}
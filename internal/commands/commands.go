@@ -4,14 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"math"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/Ciobi0212/gator.git/internal/database"
 	"github.com/Ciobi0212/gator.git/internal/requests"
+	"github.com/Ciobi0212/gator.git/internal/schedule"
 	"github.com/Ciobi0212/gator.git/internal/state"
 
 	"github.com/google/uuid"
@@ -36,18 +38,38 @@ func NewUserFacingError(message string, suggestion string) error {
 
 // Command names constants
 const (
-	CmdLogin     = "login"
-	CmdRegister  = "register"
-	CmdReset     = "reset"
-	CmdUsers     = "users"
-	CmdAgg       = "agg"
-	CmdAddFeed   = "addfeed"
-	CmdFeeds     = "feeds"
-	CmdFollow    = "follow"
-	CmdFollowing = "following"
-	CmdUnfollow  = "unfollow"
-	CmdBrowse    = "browse"
-	CmdHelp      = "help"
+	CmdLogin       = "login"
+	CmdRegister    = "register"
+	CmdReset       = "reset"
+	CmdUsers       = "users"
+	CmdAgg         = "agg"
+	CmdAddFeed     = "addfeed"
+	CmdFeeds       = "feeds"
+	CmdFollow      = "follow"
+	CmdFollowing   = "following"
+	CmdUnfollow    = "unfollow"
+	CmdBrowse      = "browse"
+	CmdImport      = "import"
+	CmdExport      = "export"
+	CmdRead        = "read"
+	CmdShow        = "show"
+	CmdDiff        = "diff"
+	CmdFilter      = "filter"
+	CmdFolder      = "folder"
+	CmdRename      = "rename"
+	CmdStar        = "star"
+	CmdUnstar      = "unstar"
+	CmdStarred     = "starred"
+	CmdPrune       = "prune"
+	CmdMarkAllRead = "mark-all-read"
+	CmdSearch      = "search"
+	CmdServe       = "serve"
+	CmdToken       = "token"
+	CmdPublish     = "publish"
+	CmdEnableFeed  = "enablefeed"
+	CmdTui         = "tui"
+	CmdCompletion  = "completion"
+	CmdHelp        = "help"
 )
 
 type Command struct {
@@ -55,72 +77,84 @@ type Command struct {
 	Params []string
 }
 
-var mapCommands = make(map[string]func(*state.AppState, []string) error)
+// Run executes the command, ctx is cancelled when the user asks gator to stop (SIGINT/SIGTERM)
+func (c *Command) Run(ctx context.Context, state *state.AppState) error {
+	def, ok := mapCommands[c.Name]
 
-func registerCommand(name string, fun func(*state.AppState, []string) error) {
-	if _, exists := mapCommands[name]; exists {
-		log.Printf("Warning: Command '%s' is being registered more than once.", name)
+	if !ok {
+		return NewUserFacingError("unknown command "+c.Name, "use gator help to see the available commands")
 	}
-	mapCommands[name] = fun
-}
 
-func InitMapCommand() {
-	registerCommand(CmdLogin, handleLogin)
-	registerCommand(CmdRegister, handleRegister)
-	registerCommand(CmdReset, handleReset)
-	registerCommand(CmdUsers, handleUsers)
-	registerCommand(CmdAgg, handleAgg)
-	registerCommand(CmdAddFeed, middlewareLoggedIn(handleAddfeed))
-	registerCommand(CmdFeeds, handleFeeds)
-	registerCommand(CmdFollow, middlewareLoggedIn(handleFollow))
-	registerCommand(CmdFollowing, middlewareLoggedIn(handleFollowing))
-	registerCommand(CmdUnfollow, middlewareLoggedIn(handleUnfollow))
-	registerCommand(CmdBrowse, middlewareLoggedIn(handleBrowse))
-	registerCommand(CmdHelp, handleHelp)
-}
+	params := c.Params
 
-func (c *Command) Run(state *state.AppState) error {
-	callback, ok := mapCommands[c.Name]
+	for len(def.Subcommands) > 0 {
+		if len(params) == 0 {
+			return def.usageError("missing subcommand")
+		}
 
-	if !ok {
-		return NewUserFacingError("unknown command "+c.Name, "")
+		if isHelpFlag(params[0]) {
+			return printCommandHelp(def)
+		}
+
+		sub := def.subcommand(params[0])
+		if sub == nil {
+			return def.usageError("unknown subcommand " + params[0])
+		}
+
+		def, params = sub, params[1:]
+	}
+
+	if !state.Out.IsText() && def.TextOnly {
+		return NewUserFacingError(
+			fmt.Sprintf("%s command has no %s output", def.Path(), state.Out.Format),
+			"run it without --output",
+		)
+	}
+
+	in, err := def.parse(params)
+	if errors.Is(err, flag.ErrHelp) {
+		return printCommandHelp(def)
+	}
+	if err != nil {
+		return err
 	}
 
-	err := callback(state, c.Params)
+	if def.RequiresLogin {
+		in.User, err = currentUser(ctx, state)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = def.Handler(ctx, state, in)
 
 	if err != nil {
-		return fmt.Errorf("error running command '%s': %w", c.Name, err)
+		return fmt.Errorf("error running command '%s': %w", def.Path(), err)
 	}
 
 	return nil
 }
 
-// Middleware
-func middlewareLoggedIn(handler func(*state.AppState, []string, database.User) error) func(*state.AppState, []string) error {
-	return func(state *state.AppState, params []string) error {
-		user, err := state.Db.FindUserByName(context.Background(), state.Cfg.Current_username)
-		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				return NewUserFacingError("user from config not found in database", "try to register first")
-			}
-			return fmt.Errorf("err exec query find user by name: %w", err)
+// currentUser is the user logged in with gator login, for the commands requiring login
+func currentUser(ctx context.Context, state *state.AppState) (database.User, error) {
+	user, err := state.Db.FindUserByName(ctx, state.Cfg.Current_username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.User{}, NewUserFacingError("user from config not found in database", "try to register first")
 		}
-
-		return handler(state, params, user)
+		return database.User{}, fmt.Errorf("err exec query find user by name: %w", err)
 	}
+
+	return user, nil
 }
 
 // Handlers
 
-func handleLogin(state *state.AppState, params []string) error {
-	if len(params) != 1 {
-		return NewUserFacingError("login command expects 1 param : <username>", "e.g: gator login paul")
-	}
-
-	username := params[0]
+func handleLogin(ctx context.Context, state *state.AppState, in *Input) error {
+	username := in.Arg("username")
 
-	_, err := state.Db.FindUserByName(
-		context.Background(),
+	user, err := state.Db.FindUserByName(
+		ctx,
 		username,
 	)
 
@@ -137,20 +171,16 @@ func handleLogin(state *state.AppState, params []string) error {
 		return fmt.Errorf("err setting user: %w", err)
 	}
 
-	fmt.Printf("Current user is %s\n", username)
-
-	return nil
+	return state.Out.Item(userRecord{Name: user.Name, CreatedAt: user.CreatedAt, Current: true}, func() {
+		fmt.Printf("Current user is %s\n", username)
+	})
 }
 
-func handleRegister(state *state.AppState, params []string) error {
-	if len(params) != 1 {
-		return NewUserFacingError("register command expects 1 param : <username>", "e.g: gator register paul")
-	}
-
-	username := params[0]
+func handleRegister(ctx context.Context, state *state.AppState, in *Input) error {
+	username := in.Arg("username")
 
-	_, err := state.Db.CreateUser(
-		context.Background(),
+	user, err := state.Db.CreateUser(
+		ctx,
 		database.CreateUserParams{
 			ID:        uuid.New(),
 			CreatedAt: time.Now().UTC(),
@@ -169,65 +199,73 @@ func handleRegister(state *state.AppState, params []string) error {
 		return fmt.Errorf("err setting user: %w", err)
 	}
 
-	fmt.Printf("Current user is %s\n", username)
-
-	return nil
+	return state.Out.Item(userRecord{Name: user.Name, CreatedAt: user.CreatedAt, Current: true}, func() {
+		fmt.Printf("Current user is %s\n", username)
+	})
 }
 
-func handleReset(state *state.AppState, params []string) error {
-	err := state.Db.DeleteAllUsers(context.Background())
+func handleReset(ctx context.Context, state *state.AppState, in *Input) error {
+	err := state.Db.DeleteAllUsers(ctx)
 
 	if err != nil {
 		return fmt.Errorf("error del users: %w", err)
 	}
 
-	fmt.Println("All users have been deleted !")
+	state.Out.Info("All users have been deleted !\n")
 
-	err = state.Db.DeleteAllFeeds(context.Background())
+	err = state.Db.DeleteAllFeeds(ctx)
 
 	if err != nil {
 		return fmt.Errorf("error del feeds: %w", err)
 	}
 
-	fmt.Println("All feeds have been deleted !")
+	state.Out.Info("All feeds have been deleted !\n")
 
 	return nil
 }
 
-func handleUsers(state *state.AppState, params []string) error {
-	users, err := state.Db.GetAllUsers(context.Background())
+func handleUsers(ctx context.Context, state *state.AppState, in *Input) error {
+	users, err := state.Db.GetAllUsers(ctx)
 
 	if err != nil {
 		return fmt.Errorf("err getting all users: %w", err)
 	}
 
+	records := make([]userRecord, 0, len(users))
 	for _, user := range users {
-		str := "* " + user.Name
+		records = append(records, userRecord{
+			Name:      user.Name,
+			CreatedAt: user.CreatedAt,
+			Current:   state.Cfg.Current_username == user.Name,
+		})
+	}
 
-		if state.Cfg.Current_username == user.Name {
-			str += " (current)"
-		}
+	return state.Out.List(records, func() {
+		for _, user := range records {
+			str := "* " + user.Name
 
-		fmt.Println(str)
-	}
+			if user.Current {
+				str += " (current)"
+			}
 
-	return nil
+			fmt.Println(str)
+		}
+	})
 }
 
-func handleAgg(state *state.AppState, params []string) error {
-	if len(params) < 1 || len(params) > 2 {
-		return NewUserFacingError("agg command requires 1-2 params: <interval> [concurrency]", "e.g: gator agg 10m 5")
-	}
+func handleAgg(ctx context.Context, state *state.AppState, in *Input) error {
+	fetchTimeout := in.Duration("timeout")
+	prune := in.Bool("prune")
 
-	timeBetweenRequests, err := time.ParseDuration(params[0])
+	timeBetweenRequests, err := time.ParseDuration(in.Arg("interval"))
 	if err != nil {
 		return NewUserFacingError("invalid input format for interval", "e.g: 10m, 1s, 2h")
 	}
 
 	// Default concurrency to 1 if not specified
 	concurrency := 1
-	if len(params) == 2 {
-		concurrency, err = strconv.Atoi(params[1])
+	if in.Arg("concurrency") != "" {
+		concurrency, err = strconv.Atoi(in.Arg("concurrency"))
 		if err != nil {
 			return NewUserFacingError("invalid input format for concurrency", "e.g: 1, 5, 10")
 		}
@@ -236,33 +274,96 @@ func handleAgg(state *state.AppState, params []string) error {
 		}
 	}
 
+	if fetchTimeout <= 0 {
+		return NewUserFacingError("timeout must be positive", "e.g: --timeout 30s")
+	}
+
+	retention := state.Cfg.Retention
+	if prune && retention.MaxAge == "" && retention.MaxPostsPerFeed <= 0 {
+		return NewUserFacingError("--prune needs a retention policy", `set "retention": {"max_age": "30d"} in ~/.gatorconfig.json`)
+	}
+
+	// Posts saved before deduplication must get their real key before their feed is fetched again
+	err = rekeyLegacyPosts(ctx, state)
+	if err != nil {
+		return err
+	}
+
 	ticker := time.NewTicker(timeBetweenRequests)
 
 	defer ticker.Stop()
 
-	fmt.Printf("Aggregator started with %d concurrent workers, fetching every %v\n", concurrency, timeBetweenRequests)
+	fmt.Printf("Aggregator started with %d concurrent workers, checking for due feeds every %v\n", concurrency, timeBetweenRequests)
+
+	// Workers are not cancelled on the first signal so the feeds they are on are not left half inserted,
+	// a second signal exits right away (see main)
+	workerCtx := context.WithoutCancel(ctx)
 
+	jobs := make(chan database.Feed)
 	wg := sync.WaitGroup{}
 
-	for ; ; <-ticker.C {
-		feeds, err := state.Db.GetNextFeedsToFetch(context.Background(), int32(concurrency))
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for feed := range jobs {
+				scrapeFeed(workerCtx, feed, state, timeBetweenRequests, fetchTimeout)
+			}
+		}()
+	}
 
-		if err != nil {
-			fmt.Println(fmt.Errorf("error getting next feed to fetch: %w", err))
-			continue
-		}
+	// A claimed feed is leased until it's scheduled again after its fetch, the lease only matters
+	// if we die in between. It covers waiting for a free worker plus the fetch itself.
+	lease := 2*fetchTimeout + time.Minute
 
-		fmt.Printf("Fetching %d feeds...\n", len(feeds))
+	// Feeds handed to workers since the last prune, there's nothing new to prune without them
+	dispatched := 0
 
-		for _, feed := range feeds {
-			wg.Add(1)
-			go scrapeFeed(feed, state, &wg)
+	for {
+		feed, err := state.Db.ClaimNextFeedToFetch(
+			ctx,
+			database.ClaimNextFeedToFetchParams{
+				LeaseUntil: time.Now().UTC().Add(lease),
+				Now:        time.Now().UTC(),
+			},
+		)
+
+		if err == nil {
+			// Blocks until a worker is free
+			select {
+			case jobs <- feed:
+				dispatched++
+				continue
+			case <-ctx.Done():
+				// Give back the lease so the feed is picked first next time
+				err = state.Db.SetFeedNextFetchAt(workerCtx, database.SetFeedNextFetchAtParams{ID: feed.ID})
+				if err != nil {
+					state.Out.Error(fmt.Errorf("error releasing feed %s: %w", feed.Name, err))
+				}
+			}
+		} else if !errors.Is(err, sql.ErrNoRows) && ctx.Err() == nil {
+			state.Out.Error(fmt.Errorf("error claiming next feed to fetch: %w", err))
 		}
 
-		wg.Wait()
-	}
+		// Nothing is due anymore, the cycle is over
+		if prune && dispatched > 0 && ctx.Err() == nil {
+			err = prunePosts(ctx, state, retention, false)
+			if err != nil {
+				state.Out.Error(fmt.Errorf("error pruning posts: %w", err))
+			}
+			dispatched = 0
+		}
 
-	return nil
+		// Nothing is due (or we are stopping), wait for the next check
+		select {
+		case <-ctx.Done():
+			close(jobs)
+			wg.Wait()
+			fmt.Println("Aggregator stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
 }
 
 func parseFeedTime(dateString string) (time.Time, error) {
@@ -286,60 +387,184 @@ func parseFeedTime(dateString string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("failed to parse date '%s' with known formats", dateString)
 }
 
-func scrapeFeed(feed database.Feed, state *state.AppState, wg *sync.WaitGroup) {
-	defer wg.Done()
+func scrapeFeed(ctx context.Context, feed database.Feed, state *state.AppState, minInterval time.Duration, fetchTimeout time.Duration) {
+	var hints requests.Hints
+	var fetchErr error
 
-	err := state.Db.MarkFeedFetched(context.Background(), feed.ID)
+	// Whatever the outcome of the fetch, decide when the feed is due again
+	defer func() {
+		if fetchErr != nil {
+			recordFetchFailure(ctx, state, feed, fetchErr, hints, minInterval)
+			return
+		}
+		scheduleNextFetch(ctx, state, feed, hints, minInterval)
+	}()
+
+	err := state.Db.MarkFeedFetched(ctx, feed.ID)
 	if err != nil {
-		fmt.Println(fmt.Errorf("error marking feed %s fetched: %w", feed.Name, err))
+		state.Out.Error(fmt.Errorf("error marking feed %s fetched: %w", feed.Name, err))
 		return
 	}
 
-	rssfeed, err := requests.FetchFeed(context.Background(), feed.Url)
+	fetchCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	result, err := requests.FetchFeed(
+		fetchCtx,
+		feed.Url,
+		requests.Validators{
+			ETag:         feed.Etag.String,
+			LastModified: feed.LastModified.String,
+		},
+	)
 	if err != nil {
-		fmt.Println(fmt.Errorf("error fetching feed %s : %w", feed.Name, err))
+		var statusErr *requests.StatusError
+		if errors.As(err, &statusErr) {
+			hints = statusErr.Hints
+		}
+
+		state.Out.Error(fmt.Errorf("error fetching feed %s : %w", feed.Name, err))
+		fetchErr = err
 		return
 	}
 
+	hints = result.Hints
+
+	err = state.Db.RecordFeedSuccess(ctx, feed.ID)
+	if err != nil {
+		state.Out.Error(fmt.Errorf("error recording success of feed %s: %w", feed.Name, err))
+	}
+
+	// 304, nothing changed since the last fetch so there are no new posts to insert
+	if result.NotModified {
+		fmt.Printf("Feed %s not modified since last fetch\n", feed.Name)
+		return
+	}
+
+	err = state.Db.SetFeedValidators(
+		ctx,
+		database.SetFeedValidatorsParams{
+			ID:           feed.ID,
+			Etag:         sql.NullString{String: result.Validators.ETag, Valid: result.Validators.ETag != ""},
+			LastModified: sql.NullString{String: result.Validators.LastModified, Valid: result.Validators.LastModified != ""},
+		},
+	)
+	if err != nil {
+		state.Out.Error(fmt.Errorf("error saving validators for feed %s: %w", feed.Name, err))
+	}
+
+	rssfeed := result.Feed
+
 	fmt.Printf("Found %v posts on feed %s!\n", len(rssfeed.Items), feed.Name)
 
-	for _, item := range rssfeed.Items {
-		publishedAt, err := parseFeedTime(item.Published)
+	created, updated := savePosts(ctx, state, feed, rssfeed.Items)
+	if created > 0 || updated > 0 {
+		fmt.Printf("Feed %s: %d new posts, %d updated\n", feed.Name, created, updated)
+	}
+}
+
+func scheduleNextFetch(ctx context.Context, state *state.AppState, feed database.Feed, hints requests.Hints, minInterval time.Duration) {
+	published, err := state.Db.GetRecentPublishedDatesForFeed(
+		ctx,
+		database.GetRecentPublishedDatesForFeedParams{
+			FeedID: feed.ID,
+			Limit:  schedule.HistorySize,
+		},
+	)
+	if err != nil {
+		state.Out.Error(fmt.Errorf("error getting publish dates for feed %s: %w", feed.Name, err))
+	}
+
+	nextFetchAt := schedule.NextFetch(time.Now().UTC(), published, hints, minInterval)
+
+	err = state.Db.SetFeedNextFetchAt(
+		ctx,
+		database.SetFeedNextFetchAtParams{
+			ID:          feed.ID,
+			NextFetchAt: sql.NullTime{Time: nextFetchAt, Valid: true},
+		},
+	)
+	if err != nil {
+		state.Out.Error(fmt.Errorf("error scheduling feed %s: %w", feed.Name, err))
+	}
+}
+
+// recordFetchFailure backs off exponentially on feeds that keep failing and disables them
+// after schedule.DisableAfterFailures failures in a row
+func recordFetchFailure(ctx context.Context, state *state.AppState, feed database.Feed, fetchErr error, hints requests.Hints, minInterval time.Duration) {
+	failures, err := state.Db.RecordFeedFailure(
+		ctx,
+		database.RecordFeedFailureParams{
+			ID:        feed.ID,
+			LastError: sql.NullString{String: fetchErr.Error(), Valid: true},
+		},
+	)
+	if err != nil {
+		state.Out.Error(fmt.Errorf("error recording failure of feed %s: %w", feed.Name, err))
+		return
+	}
+
+	if failures >= schedule.DisableAfterFailures {
+		err = state.Db.DisableFeed(ctx, feed.ID)
 		if err != nil {
-			fmt.Printf("error parsing PubDate: %v\n", err)
-			publishedAt = time.Time{}
-		}
-
-		// URL is unique, so if the post already is in the DB it will simply not inserted (see posts.sql)
-		_, err = state.Db.CreatePost(
-			context.Background(),
-			database.CreatePostParams{
-				CreatedAt:   time.Now().UTC(),
-				UpdatedAt:   time.Now().UTC(),
-				Title:       item.Title,
-				Url:         item.Link,
-				PublishedAt: publishedAt,
-				Description: item.Description,
-				FeedID:      feed.ID,
-			},
+			state.Out.Error(fmt.Errorf("error disabling feed %s: %w", feed.Name, err))
+			return
+		}
+
+		fmt.Printf("Feed %s disabled after %d failed fetches in a row\n", feed.Name, failures)
+		return
+	}
+
+	err = state.Db.SetFeedNextFetchAt(
+		ctx,
+		database.SetFeedNextFetchAtParams{
+			ID:          feed.ID,
+			NextFetchAt: sql.NullTime{Time: schedule.Backoff(time.Now().UTC(), failures, hints, minInterval), Valid: true},
+		},
+	)
+	if err != nil {
+		state.Out.Error(fmt.Errorf("error scheduling feed %s: %w", feed.Name, err))
+	}
+}
+
+func handleAddfeed(ctx context.Context, state *state.AppState, in *Input) error {
+	name, url := in.Arg("name"), in.Arg("url")
+
+	// Only a discovered feed can be named after its title
+	if name == "" && !in.Bool("discover") {
+		return NewUserFacingError(
+			"addfeed command needs a name: <name> <url>, or --discover [name] <site-url>",
+			"e.g: gator addfeed example https://example.com/feed, gator addfeed --discover https://go.dev/blog",
 		)
+	}
+
+	var candidate requests.Candidate
+	var err error
 
-		if err != nil && !errors.Is(err, sql.ErrNoRows) {
-			fmt.Println(fmt.Errorf("err creating post %s: %w", item.Title, err))
-			continue
+	if in.Bool("discover") {
+		candidate, err = discoverFeed(ctx, state, url)
+		if err != nil {
+			return err
 		}
+
+		url = candidate.URL
 	}
-}
 
-func handleAddfeed(state *state.AppState, params []string, user database.User) error {
-	if len(params) != 2 {
-		return NewUserFacingError("addfeed command needs 2 params: <name> <url>", "e.g: gator addfeed example htttp://example.com/feed")
+	var result *requests.FetchResult
+
+	if !in.Bool("no-verify") {
+		result, err = verifyFeed(ctx, state, url)
+		if err != nil {
+			return err
+		}
 	}
 
-	name, url := params[0], params[1]
+	if name == "" {
+		name = feedName(candidate, result)
+	}
 
 	feed, err := state.Db.CreateFeed(
-		context.Background(),
+		ctx,
 		database.CreateFeedParams{
 			Name:      name,
 			Url:       url,
@@ -353,9 +578,9 @@ func handleAddfeed(state *state.AppState, params []string, user database.User) e
 	}
 
 	createFeedFollowRow, err := state.Db.CreateFeedFollow(
-		context.Background(),
+		ctx,
 		database.CreateFeedFollowParams{
-			UserID:    user.ID,
+			UserID:    in.User.ID,
 			FeedID:    feed.ID,
 			CreatedAt: time.Now().UTC(),
 			UpdatedAt: time.Now().UTC(),
@@ -366,40 +591,124 @@ func handleAddfeed(state *state.AppState, params []string, user database.User) e
 		return fmt.Errorf("err creating feed_follow entry: %w", err)
 	}
 
-	fmt.Printf("User %s now follows %s\n", createFeedFollowRow.Name, createFeedFollowRow.Name_2)
+	state.Out.Info("User %s now follows %s\n", createFeedFollowRow.Name, createFeedFollowRow.FeedName)
+
+	if result != nil {
+		// The feed is followed already, without its first posts agg fetches it right away instead
+		err = seedFeed(ctx, state, feed, result)
+		if err != nil {
+			state.Out.Info("Warning: couldn't save the first posts of %s, gator agg will fetch them\n", feed.Name)
+		}
 
-	fmt.Printf("%v\n", feed)
+		// seedFeed recorded the fetch
+		feed, err = state.Db.FindFeedByURL(ctx, feed.Url)
+		if err != nil {
+			return fmt.Errorf("err query findFeedByUrl: %w", err)
+		}
+	}
 
-	return nil
+	return state.Out.Item(toFeedRecord(feed), func() {
+		fmt.Printf("Feed ID: %d\nFeed Name: %s\nURL: %s\n", feed.ID, feed.Name, feed.Url)
+	})
 }
 
-func handleFeeds(state *state.AppState, params []string) error {
-	if len(params) != 0 {
-		return NewUserFacingError("no params needed for feed command", "e.g: gator feeds")
+func handleFeeds(ctx context.Context, state *state.AppState, in *Input) error {
+	if in.Bool("broken") {
+		return printBrokenFeeds(ctx, state)
 	}
 
-	feeds, err := state.Db.GetAllFeeds(context.Background())
+	feeds, err := state.Db.GetAllFeeds(ctx)
 
 	if err != nil {
 		return fmt.Errorf("err getting all feeds: %w", err)
 	}
 
-	for _, feed := range feeds {
-		fmt.Printf("Feed Name: %s\nURL: %s\n", feed.Name, feed.Url)
-		fmt.Println("--------------")
+	return state.Out.List(toFeedRecords(feeds), func() {
+		for _, feed := range feeds {
+			fmt.Printf("Feed Name: %s\nURL: %s\n", feed.Name, feed.Url)
+			if feed.DisabledAt.Valid {
+				fmt.Println("Disabled, see gator feeds --broken")
+			}
+			fmt.Println("--------------")
+		}
+	})
+}
+
+func printBrokenFeeds(ctx context.Context, state *state.AppState) error {
+	feeds, err := state.Db.GetBrokenFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("err getting broken feeds: %w", err)
 	}
 
-	return nil
+	return state.Out.List(toFeedRecords(feeds), func() {
+		if len(feeds) == 0 {
+			fmt.Println("All feeds are fetching fine")
+			return
+		}
+
+		for _, feed := range feeds {
+			fmt.Printf("Feed Name: %s\nURL: %s\n", feed.Name, feed.Url)
+			fmt.Printf("Failed fetches in a row: %d\n", feed.ConsecutiveFailures)
+			fmt.Printf("Last error: %s\n", feed.LastError.String)
+
+			if feed.LastSuccessAt.Valid {
+				fmt.Printf("Last success: %s\n", feed.LastSuccessAt.Time.Format(time.DateTime))
+			} else {
+				fmt.Println("Last success: never")
+			}
+
+			if feed.DisabledAt.Valid {
+				fmt.Printf("Disabled since %s, use gator enablefeed %s once fixed\n", feed.DisabledAt.Time.Format(time.DateTime), feed.Url)
+			}
+
+			fmt.Println("--------------")
+		}
+	})
 }
 
-func handleFollow(state *state.AppState, params []string, user database.User) error {
-	if len(params) != 1 {
-		return NewUserFacingError("follow commands needs 1 param: <url>", "e.g: gator follow http://example.com")
+func handleEnableFeed(ctx context.Context, state *state.AppState, in *Input) error {
+	feed, err := state.Db.FindFeedByURL(ctx, in.Arg("url"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewUserFacingError("no feed with specified url exists in your db", "use gator feeds --broken to see disabled feeds")
+		}
+		return fmt.Errorf("err query findFeedByUrl: %w", err)
 	}
 
-	url := params[0]
+	err = state.Db.EnableFeed(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("err enabling feed: %w", err)
+	}
 
-	feed, err := state.Db.FindFeedByURL(context.Background(), url)
+	feed, err = state.Db.FindFeedByURL(ctx, feed.Url)
+	if err != nil {
+		return fmt.Errorf("err query findFeedByUrl: %w", err)
+	}
+
+	return state.Out.Item(toFeedRecord(feed), func() {
+		fmt.Printf("Feed %s enabled, it will be fetched on the next agg check\n", feed.Name)
+	})
+}
+
+func handleFollow(ctx context.Context, state *state.AppState, in *Input) error {
+	folder := in.String("folder")
+
+	var folderID sql.NullInt32
+	var err error
+
+	if folder != "" {
+		folder, err = folderName(folder)
+		if err != nil {
+			return err
+		}
+
+		folderID, err = findFolder(ctx, state, in.User, folder)
+		if err != nil {
+			return err
+		}
+	}
+
+	feed, err := state.Db.FindFeedByURL(ctx, in.Arg("url"))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return NewUserFacingError("no feed with specified url exists in your db", "use gator feeds to see available feeds or add a new one using gator addfeed")
@@ -408,12 +717,13 @@ func handleFollow(state *state.AppState, params []string, user database.User) er
 	}
 
 	createFeedFollowRow, err := state.Db.CreateFeedFollow(
-		context.Background(),
+		ctx,
 		database.CreateFeedFollowParams{
-			UserID:    user.ID,
+			UserID:    in.User.ID,
 			FeedID:    feed.ID,
 			CreatedAt: time.Now().UTC(),
 			UpdatedAt: time.Now().UTC(),
+			FolderID:  folderID,
 		},
 	)
 
@@ -421,36 +731,47 @@ func handleFollow(state *state.AppState, params []string, user database.User) er
 		return fmt.Errorf("err creating feed_follow entry: %w", err)
 	}
 
-	fmt.Printf("User %s now follows %s\n", createFeedFollowRow.Name, createFeedFollowRow.Name_2)
+	record := followRecord{
+		FeedID: feed.ID,
+		Name:   createFeedFollowRow.FeedName,
+		URL:    feed.Url,
+		Folder: folder,
+	}
 
-	return nil
+	return state.Out.Item(record, func() {
+		fmt.Printf("User %s now follows %s\n", createFeedFollowRow.Name, createFeedFollowRow.FeedName)
+	})
 }
 
-func handleFollowing(state *state.AppState, params []string, user database.User) error {
-	if len(params) != 0 {
-		return NewUserFacingError("no params required for following command", "e.g: gator following")
-	}
-
-	results, err := state.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+func handleFollowing(ctx context.Context, state *state.AppState, in *Input) error {
+	results, err := state.Db.GetFeedFollowsForUser(ctx, in.User.ID)
 	if err != nil {
 		return fmt.Errorf("err getting feeds for user: %w", err)
 	}
 
-	for _, result := range results {
-		fmt.Printf("%s  %s\n", result.Name, result.Url)
+	folders, err := state.Db.GetFoldersForUser(ctx, in.User.ID)
+	if err != nil {
+		return fmt.Errorf("err getting folders for user: %w", err)
 	}
 
-	return nil
-}
-
-func handleUnfollow(state *state.AppState, params []string, user database.User) error {
-	if len(params) != 1 {
-		return NewUserFacingError("unfollow command needs 1 param: <url>", "e.g: gator unfollow http://example.com")
+	records := make([]followRecord, 0, len(results))
+	for _, follow := range results {
+		records = append(records, followRecord{
+			FeedID:      follow.ID,
+			Name:        follow.Name,
+			URL:         follow.Url,
+			Folder:      follow.Folder.String,
+			UnreadCount: follow.UnreadCount,
+		})
 	}
 
-	url := params[0]
+	return state.Out.List(records, func() {
+		printFollowingTree(results, folders)
+	})
+}
 
-	feed, err := state.Db.FindFeedByURL(context.Background(), url)
+func handleUnfollow(ctx context.Context, state *state.AppState, in *Input) error {
+	feed, err := state.Db.FindFeedByURL(ctx, in.Arg("url"))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return NewUserFacingError("no feed with specified url exists in your db", "use gator following to see the feeds you are following")
@@ -460,9 +781,9 @@ func handleUnfollow(state *state.AppState, params []string, user database.User)
 	}
 
 	err = state.Db.DeleteFeedFollowsEntry(
-		context.Background(),
+		ctx,
 		database.DeleteFeedFollowsEntryParams{
-			UserID: user.ID,
+			UserID: in.User.ID,
 			FeedID: feed.ID,
 		},
 	)
@@ -474,106 +795,124 @@ func handleUnfollow(state *state.AppState, params []string, user database.User)
 	return nil
 }
 
-func handleBrowse(state *state.AppState, params []string, user database.User) error {
-	if len(params) != 1 {
-		return NewUserFacingError("browse command accepts 1 param: <numOfPosts>", "e.g: gator browse 10")
+func handleBrowse(ctx context.Context, state *state.AppState, in *Input) error {
+	folder, feedURL, author := in.String("folder"), in.String("feed"), in.String("author")
+	since, until := in.String("since"), in.String("until")
+	order, page, offset := in.String("order"), in.Int("page"), in.Int("offset")
+
+	limit := 10
+	var err error
+
+	if folder != "" {
+		folder, err = folderName(folder)
+		if err != nil {
+			return err
+		}
+
+		// A typo would only show no posts
+		err = checkFolderPath(ctx, state, in.User, folder)
+		if err != nil {
+			return err
+		}
+	}
+
+	if in.Arg("limit") != "" {
+		limit, err = strconv.Atoi(in.Arg("limit"))
+		if err != nil {
+			return NewUserFacingError("input is not number", "e.g: gator browse 10")
+		}
+		if limit < 1 {
+			return NewUserFacingError("limit must be at least 1", "e.g: gator browse 10")
+		}
+	}
+
+	if page < 1 || offset < 0 {
+		return NewUserFacingError("--page starts at 1 and --offset can't be negative", "e.g: gator browse --page 2 10")
 	}
 
-	limit, err := strconv.Atoi(params[0])
+	// The query takes int32, bigger values would wrap around
+	tooBig := NewUserFacingError("limit, --page or --offset is too big", fmt.Sprintf("browse skips and shows up to %d posts", math.MaxInt32))
+	if limit > math.MaxInt32 || page > math.MaxInt32 || offset > math.MaxInt32 {
+		return tooBig
+	}
 
-	if err != nil {
-		return NewUserFacingError("input is not number", "e.g: gator browse 10")
+	// --offset wins over --page when both are given
+	skip := (page - 1) * limit
+	if offset > 0 {
+		skip = offset
+	}
+	if skip > math.MaxInt32 {
+		return tooBig
 	}
 
-	posts, err := state.Db.GetPostsForUser(
-		context.Background(),
-		database.GetPostsForUserParams{
-			UserID: user.ID,
-			Limit:  int32(limit),
-		},
-	)
+	arg := database.GetPostsForUserParams{
+		UserID:      in.User.ID,
+		UnreadOnly:  in.Bool("unread"),
+		Folder:      sql.NullString{String: folder, Valid: folder != ""},
+		FeedUrl:     sql.NullString{String: feedURL, Valid: feedURL != ""},
+		Author:      sql.NullString{String: author, Valid: author != ""},
+		OldestFirst: order == "asc",
+		Limit:       int32(limit),
+		Offset:      int32(skip),
+	}
+
+	if since != "" {
+		arg.Since.Time, err = parseSince(since)
+		if err != nil {
+			return NewUserFacingError("invalid --since value", "e.g: 2024-01-31, 72h or 30d")
+		}
+		arg.Since.Valid = true
+	}
+
+	if until != "" {
+		arg.Until.Time, err = parseUntil(until)
+		if err != nil {
+			return NewUserFacingError("invalid --until value", "e.g: 2024-01-31, 72h or 30d")
+		}
+		arg.Until.Valid = true
+	}
+
+	posts, err := state.Db.GetPostsForUser(ctx, arg)
 
 	if err != nil {
 		return fmt.Errorf("err getting posts for user: %w", err)
 	}
 
+	records := make([]postRecord, 0, len(posts))
 	for _, post := range posts {
-		fmt.Println("------------")
-		fmt.Printf("Title: %s\n", post.Title)
-		fmt.Printf("Link: %s\n", post.Url)
-		fmt.Println("------------")
-	}
+		records = append(records, postRecord{
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			Feed:        post.FeedName,
+			PublishedAt: post.PublishedAt,
+			IsRead:      post.IsRead,
+		})
+	}
+
+	err = state.Out.List(records, func() {
+		if len(posts) == 0 {
+			fmt.Println("No posts found")
+			return
+		}
 
-	return nil
-}
+		for _, post := range posts {
+			fmt.Println("------------")
+			fmt.Printf("ID: %d\n", post.ID)
+			fmt.Printf("Title: %s\n", post.Title)
+			fmt.Printf("Feed: %s · %s\n", post.FeedName, post.PublishedAt.Format(time.DateTime))
+			fmt.Printf("Link: %s\n", post.Url)
+			fmt.Println("------------")
+		}
+	})
+	if err != nil {
+		return err
+	}
 
-func handleHelp(state *state.AppState, params []string) error {
-	fmt.Println("Gator - RSS Feed Aggregator")
-	fmt.Println("===========================")
-	fmt.Println()
-
-	// App description
-	fmt.Println("ABOUT:")
-	fmt.Println("  Gator is a command-line RSS feed aggregator that helps you follow")
-	fmt.Println("  and discover content from your favorite websites using RSS feeds.")
-	fmt.Println("  It allows you to register as a user, subscribe to multiple feeds,")
-	fmt.Println("  and browse the latest posts all from your terminal.")
-	fmt.Println()
-
-	fmt.Println("HOW IT WORKS:")
-	fmt.Println("  1. Register or login to your account")
-	fmt.Println("  2. Add or follow RSS feeds you're interested in")
-	fmt.Println("  3. Start the aggregator to fetch the latest content")
-	fmt.Println("  4. Browse posts from your followed feeds")
-	fmt.Println()
-
-	fmt.Println("WORKFLOW EXAMPLE:")
-	fmt.Println("  ./gator register john         # Create a user account")
-	fmt.Println("  ./gator addfeed 'Tech News' https://example.com/rss  # Add a feed")
-	fmt.Println("  ./gator agg 10m 5 &             # Start aggregation in background (every 10 min) with 5 concurrent scrapers")
-	fmt.Println("  ./gator browse 20             # View the 20 most recent posts")
-	fmt.Println()
-
-	fmt.Println("AVAILABLE COMMANDS:")
-	fmt.Println()
-
-	// User management commands
-	fmt.Println("User Management:")
-	fmt.Println("  register <username>       - Create a new user account and login")
-	fmt.Println("  login <username>          - Login as an existing user")
-	fmt.Println("  users                     - List all registered users")
-
-	// Feed management commands
-	fmt.Println()
-	fmt.Println("Feed Management:")
-	fmt.Println("  addfeed <name> <url>      - Add a new RSS feed and follow it (requires login)")
-	fmt.Println("  feeds                     - List all available feeds")
-	fmt.Println("  follow <url>              - Follow an existing feed (requires login)")
-	fmt.Println("  following                 - List all feeds you're following (requires login)")
-	fmt.Println("  unfollow <url>            - Unfollow a feed (requires login)")
-
-	// Content viewing commands
-	fmt.Println()
-	fmt.Println("Content:")
-	fmt.Println("  browse <limit>            - View posts from feeds you follow (requires login)")
-	fmt.Println("                              limit: number of posts to display")
-
-	// System commands
-	fmt.Println()
-	fmt.Println("System:")
-	fmt.Println("  agg <interval> [concurrency]  - Start feed aggregation process")
-	fmt.Println("                              interval: time between fetches (e.g., 1s, 1m, 1h)")
-	fmt.Println("                              concurrency: number of feeds to fetch in parallel (default: 1)")
-	fmt.Println("  reset                     - Delete all users and feeds (use with caution)")
-	fmt.Println("  help                      - Display this help information")
-
-	// Examples
-	fmt.Println()
-	fmt.Println("Examples:")
-	fmt.Println("  ./gator register john     - Create a user named 'john'")
-	fmt.Println("  ./gator addfeed 'Boot.dev Blog' https://blog.boot.dev/index.xml")
-	fmt.Println("  ./gator agg 30s           - Fetch new content every 30 seconds")
-	fmt.Println("  ./gator browse 10         - Show the 10 most recent posts")
+	// A full page means there may be more
+	if len(posts) == limit {
+		state.Out.Info("Showing posts %d to %d, see the next ones with --offset %d\n", skip+1, skip+len(posts), skip+len(posts))
+	}
 
 	return nil
 }
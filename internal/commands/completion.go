@@ -0,0 +1,422 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Ciobi0212/gator.git/internal/output"
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+// The completion scripts are generated from the definitions, so a new command, flag or
+// subcommand is completed as soon as it's registered. What to offer for an arg or a flag value
+// is a spec: @files, @none, @<kind> for the values the script gets from gator completion values,
+// or the choices separated by spaces.
+//
+// bash and zsh share the functions giving the specs, only the part reading the command line
+// and offering the candidates is written for each shell.
+
+func handleCompletionBash(ctx context.Context, state *state.AppState, in *Input) error {
+	fmt.Print("# bash completion for gator, generated by gator completion bash\n\n" + shellSpecFunctions() + bashCompletion)
+	return nil
+}
+
+func handleCompletionZsh(ctx context.Context, state *state.AppState, in *Input) error {
+	fmt.Print("#compdef gator\n# zsh completion for gator, generated by gator completion zsh\n\n" + shellSpecFunctions() + zshCompletion)
+	return nil
+}
+
+func handleCompletionFish(ctx context.Context, state *state.AppState, in *Input) error {
+	fmt.Print("# fish completion for gator, generated by gator completion fish\n\n" + fishSpecFunctions() + fishCompletion)
+	return nil
+}
+
+// handleCompletionValues prints the values looked up in the database, for the scripts
+func handleCompletionValues(ctx context.Context, state *state.AppState, in *Input) error {
+	var values []string
+
+	switch Completion(in.Arg("kind")) {
+	case CompleteUsers:
+		users, err := state.Db.GetAllUsers(ctx)
+		if err != nil {
+			return fmt.Errorf("err exec query get all users: %w", err)
+		}
+		for _, user := range users {
+			values = append(values, user.Name)
+		}
+	case CompleteFeeds:
+		feeds, err := state.Db.GetAllFeeds(ctx)
+		if err != nil {
+			return fmt.Errorf("err exec query get all feeds: %w", err)
+		}
+		for _, feed := range feeds {
+			values = append(values, feed.Url)
+		}
+	case CompleteFollowedFeeds:
+		user, err := currentUser(ctx, state)
+		if err != nil {
+			return err
+		}
+		follows, err := state.Db.GetFeedFollowsForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("err exec query get feed follows for user: %w", err)
+		}
+		for _, follow := range follows {
+			values = append(values, follow.Url)
+		}
+	case CompleteFolders:
+		user, err := currentUser(ctx, state)
+		if err != nil {
+			return err
+		}
+		folders, err := state.Db.GetFoldersForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("err exec query get folders for user: %w", err)
+		}
+		for _, folder := range folders {
+			values = append(values, folder.Name)
+		}
+	default:
+		return fmt.Errorf("no values to complete for %s", in.Arg("kind"))
+	}
+
+	for _, value := range values {
+		fmt.Println(value)
+	}
+
+	return nil
+}
+
+// walkDefinitions visits every command and subcommand in registration order
+func walkDefinitions(visit func(def *Definition)) {
+	var walk func(defs []*Definition)
+	walk = func(defs []*Definition) {
+		for _, def := range defs {
+			visit(def)
+			walk(def.Subcommands)
+		}
+	}
+	walk(definitions)
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(definitions))
+	for _, def := range definitions {
+		names = append(names, def.Name)
+	}
+	return names
+}
+
+func completionSpec(complete Completion, choices []string) string {
+	switch complete {
+	case "":
+		if len(choices) == 0 {
+			return "@none"
+		}
+		return strings.Join(choices, " ")
+	case CompleteCommands:
+		return strings.Join(commandNames(), " ")
+	}
+	return "@" + string(complete)
+}
+
+// argSpecs calls add with the position of each arg having something to complete,
+// a variadic arg is only completed at its first position
+func argSpecs(def *Definition, add func(position string, spec string)) {
+	for i, arg := range def.Args {
+		spec := completionSpec(arg.Complete, arg.Choices)
+		if spec == "@none" {
+			continue
+		}
+		add(fmt.Sprintf("%s:%d", def.Path(), i), spec)
+	}
+}
+
+// shortDescription is the first sentence of a description, for the shells showing one next to each candidate
+func shortDescription(description string) string {
+	short, _, _ := strings.Cut(description, ". ")
+	return short
+}
+
+// shellSpecFunctions writes the bash and zsh functions answering what a command takes
+func shellSpecFunctions() string {
+	var b strings.Builder
+
+	b.WriteString("# _gator_subcommands prints the subcommands of a command, the commands for gator itself\n")
+	b.WriteString("_gator_subcommands() {\n    case \"$1\" in\n")
+	fmt.Fprintf(&b, "        \"\") echo %q ;;\n", strings.Join(commandNames(), " "))
+	walkDefinitions(func(def *Definition) {
+		if len(def.Subcommands) == 0 {
+			return
+		}
+		names := make([]string, 0, len(def.Subcommands))
+		for _, sub := range def.Subcommands {
+			names = append(names, sub.Name)
+		}
+		fmt.Fprintf(&b, "        %q) echo %q ;;\n", def.Path(), strings.Join(names, " "))
+	})
+	b.WriteString("    esac\n}\n\n")
+
+	b.WriteString("# _gator_flags prints the flags of a command\n")
+	b.WriteString("_gator_flags() {\n    case \"$1\" in\n")
+	walkDefinitions(func(def *Definition) {
+		if len(def.Flags) == 0 {
+			return
+		}
+		names := make([]string, 0, len(def.Flags))
+		for _, f := range def.Flags {
+			names = append(names, "--"+f.Name)
+		}
+		fmt.Fprintf(&b, "        %q) echo %q ;;\n", def.Path(), strings.Join(names, " "))
+	})
+	b.WriteString("    esac\n}\n\n")
+
+	b.WriteString("# _gator_flag_spec prints the spec of the value of a flag, nothing for a boolean flag\n")
+	b.WriteString("_gator_flag_spec() {\n    case \"$1 $2\" in\n")
+	fmt.Fprintf(&b, "        *\" --output\") echo %q ;;\n", strings.Join(output.Names(), " "))
+	walkDefinitions(func(def *Definition) {
+		for _, f := range def.Flags {
+			if f.Kind == FlagBool {
+				continue
+			}
+			fmt.Fprintf(&b, "        %q) echo %q ;;\n", def.Path()+" --"+f.Name, completionSpec(f.Complete, f.Choices))
+		}
+	})
+	b.WriteString("    esac\n}\n\n")
+
+	b.WriteString("# _gator_arg_spec prints the spec of the arg of a command at a position, from 0\n")
+	b.WriteString("_gator_arg_spec() {\n    case \"$1:$2\" in\n")
+	walkDefinitions(func(def *Definition) {
+		argSpecs(def, func(position string, spec string) {
+			fmt.Fprintf(&b, "        %q) echo %q ;;\n", position, spec)
+		})
+	})
+	b.WriteString("    esac\n}\n\n")
+
+	return b.String()
+}
+
+// fishSpecFunctions writes the same functions for fish, with the descriptions fish shows
+func fishSpecFunctions() string {
+	var b strings.Builder
+
+	b.WriteString("function __gator_subcommands --description 'Print the subcommands of a command, the commands for gator itself'\n")
+	b.WriteString("    switch \"$argv[1]\"\n        case ''\n")
+	for _, def := range definitions {
+		fmt.Fprintf(&b, "            printf '%%s\\t%%s\\n' %s %s\n", fishQuote(def.Name), fishQuote(shortDescription(def.Description)))
+	}
+	walkDefinitions(func(def *Definition) {
+		if len(def.Subcommands) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "        case %s\n", fishQuote(def.Path()))
+		for _, sub := range def.Subcommands {
+			fmt.Fprintf(&b, "            printf '%%s\\t%%s\\n' %s %s\n", fishQuote(sub.Name), fishQuote(shortDescription(sub.Description)))
+		}
+	})
+	b.WriteString("    end\nend\n\n")
+
+	b.WriteString("function __gator_flags --description 'Print the flags of a command'\n")
+	b.WriteString("    printf '%s\\t%s\\n' --output 'Output format: " + strings.Join(output.Names(), ", ") + "'\n")
+	b.WriteString("    switch \"$argv[1]\"\n")
+	walkDefinitions(func(def *Definition) {
+		if len(def.Flags) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "        case %s\n", fishQuote(def.Path()))
+		for _, f := range def.Flags {
+			fmt.Fprintf(&b, "            printf '%%s\\t%%s\\n' %s %s\n", fishQuote("--"+f.Name), fishQuote(f.Usage))
+		}
+	})
+	b.WriteString("    end\nend\n\n")
+
+	b.WriteString("function __gator_flag_spec --description 'Print the spec of the value of a flag, nothing for a boolean flag'\n")
+	b.WriteString("    switch \"$argv[1] $argv[2]\"\n")
+	fmt.Fprintf(&b, "        case '* --output'\n            echo %s\n", fishQuote(strings.Join(output.Names(), " ")))
+	walkDefinitions(func(def *Definition) {
+		for _, f := range def.Flags {
+			if f.Kind == FlagBool {
+				continue
+			}
+			fmt.Fprintf(&b, "        case %s\n            echo %s\n", fishQuote(def.Path()+" --"+f.Name), fishQuote(completionSpec(f.Complete, f.Choices)))
+		}
+	})
+	b.WriteString("    end\nend\n\n")
+
+	b.WriteString("function __gator_arg_spec --description 'Print the spec of the arg of a command at a position, from 0'\n")
+	b.WriteString("    switch \"$argv[1]:$argv[2]\"\n")
+	walkDefinitions(func(def *Definition) {
+		argSpecs(def, func(position string, spec string) {
+			fmt.Fprintf(&b, "        case %s\n            echo %s\n", fishQuote(position), fishQuote(spec))
+		})
+	})
+	b.WriteString("    end\nend\n\n")
+
+	return b.String()
+}
+
+// fishQuote quotes a word for fish, where only \ and ' are special between single quotes
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+const bashCompletion = `_gator() {
+    local cur prev words cword
+    if declare -F _init_completion >/dev/null 2>&1; then
+        # Keeps the urls in one word, : breaks words otherwise
+        _init_completion -n : || return
+    else
+        words=("${COMP_WORDS[@]}")
+        cword=$COMP_CWORD
+        cur=${COMP_WORDS[COMP_CWORD]}
+    fi
+
+    # Find the command and the position of the arg under the cursor, skipping flags and their values
+    local cmd="" flag="" npos=0 i word
+    for ((i = 1; i < cword; i++)); do
+        word=${words[i]}
+        if [[ -n $flag ]]; then
+            flag=""
+        elif [[ $word == -* ]]; then
+            [[ -n $(_gator_flag_spec "$cmd" "$word") ]] && flag=$word
+        elif [[ -z $cmd ]]; then
+            cmd=$word
+        elif [[ -n $(_gator_subcommands "$cmd") ]]; then
+            cmd="$cmd $word"
+        else
+            ((npos++))
+        fi
+    done
+
+    local spec
+    if [[ -n $flag ]]; then
+        spec=$(_gator_flag_spec "$cmd" "$flag")
+    elif [[ $cur == -* ]]; then
+        spec="--output $(_gator_flags "$cmd")"
+    elif [[ -z $cmd || -n $(_gator_subcommands "$cmd") ]]; then
+        spec=$(_gator_subcommands "$cmd")
+    else
+        spec=$(_gator_arg_spec "$cmd" "$npos")
+    fi
+
+    local values
+    case $spec in
+        @files) COMPREPLY=($(compgen -f -- "$cur")) ;;
+        @none | "") COMPREPLY=() ;;
+        @*)
+            values=$(gator completion values "${spec#@}" 2>/dev/null) || return
+            COMPREPLY=($(compgen -W "$values" -- "$cur"))
+            ;;
+        *) COMPREPLY=($(compgen -W "$spec" -- "$cur")) ;;
+    esac
+
+    if declare -F __ltrim_colon_completions >/dev/null 2>&1; then
+        __ltrim_colon_completions "$cur"
+    fi
+}
+
+complete -F _gator gator
+`
+
+const zshCompletion = `_gator() {
+    # Find the command and the position of the arg under the cursor, skipping flags and their values
+    local cmd="" flag="" npos=0 i word
+    for ((i = 2; i < CURRENT; i++)); do
+        word=${words[i]}
+        if [[ -n $flag ]]; then
+            flag=""
+        elif [[ $word == -* ]]; then
+            [[ -n $(_gator_flag_spec "$cmd" "$word") ]] && flag=$word
+        elif [[ -z $cmd ]]; then
+            cmd=$word
+        elif [[ -n $(_gator_subcommands "$cmd") ]]; then
+            cmd="$cmd $word"
+        else
+            ((npos++))
+        fi
+    done
+
+    local cur=${words[CURRENT]} spec
+    if [[ -n $flag ]]; then
+        spec=$(_gator_flag_spec "$cmd" "$flag")
+    elif [[ $cur == -* ]]; then
+        spec="--output $(_gator_flags "$cmd")"
+    elif [[ -z $cmd || -n $(_gator_subcommands "$cmd") ]]; then
+        spec=$(_gator_subcommands "$cmd")
+    else
+        spec=$(_gator_arg_spec "$cmd" "$npos")
+    fi
+
+    local values
+    case $spec in
+        @files) _files ;;
+        @none | "") return 1 ;;
+        @*)
+            values=$(gator completion values "${spec#@}" 2>/dev/null) || return 1
+            compadd -- ${(f)values}
+            ;;
+        *) compadd -- ${=spec} ;;
+    esac
+}
+
+compdef _gator gator
+`
+
+const fishCompletion = `function __gator_complete --description 'Print the candidates for the word under the cursor'
+    # Find the command and the position of the arg under the cursor, skipping flags and their values
+    set -l words (commandline -opc)
+    set -e words[1]
+    set -l cmd ''
+    set -l flag ''
+    set -l npos 0
+    for word in $words
+        if test -n "$flag"
+            set flag ''
+        else if string match -q -- '-*' $word
+            set -l spec (__gator_flag_spec "$cmd" $word)
+            if test -n "$spec"
+                set flag $word
+            end
+        else if test -z "$cmd"
+            set cmd $word
+        else
+            set -l subcommands (__gator_subcommands "$cmd")
+            if set -q subcommands[1]
+                set cmd "$cmd $word"
+            else
+                set npos (math $npos + 1)
+            end
+        end
+    end
+
+    set -l cur (commandline -ct)
+    set -l spec
+    if test -n "$flag"
+        set spec (__gator_flag_spec "$cmd" $flag)
+    else if string match -q -- '-*' $cur
+        __gator_flags "$cmd"
+        return
+    else
+        set -l subcommands (__gator_subcommands "$cmd")
+        if set -q subcommands[1]
+            printf '%s\n' $subcommands
+            return
+        end
+        set spec (__gator_arg_spec "$cmd" $npos)
+    end
+
+    switch "$spec"
+        case @files
+            __fish_complete_path $cur
+        case @none ''
+        case '@*'
+            set -l values (gator completion values (string sub -s 2 -- $spec) 2>/dev/null)
+            and printf '%s\n' $values
+        case '*'
+            string split ' ' -- $spec
+    end
+end
+
+complete -c gator -f -a '(__gator_complete)'
+`
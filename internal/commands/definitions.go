@@ -0,0 +1,477 @@
+package commands
+
+// Flags shared by the commands listing posts
+var (
+	sinceFlag = Flag{Name: "since", Value: "date|duration", Usage: "only posts published after this date or duration ago, e.g. 2024-01-31, 72h or 30d"}
+	untilFlag = Flag{Name: "until", Value: "date|duration", Usage: "only posts published before this date or duration ago, a date includes its whole day"}
+)
+
+// postIDArg is the param of the commands working on a single post
+var postIDArg = Arg{Name: "post-id", Usage: "id of the post, as shown by gator browse"}
+
+func InitMapCommand() {
+	// User management
+	registerCommand(&Definition{
+		Name:        CmdRegister,
+		Group:       GroupUsers,
+		Description: "Create a new user account and login",
+		Args:        []Arg{{Name: "username"}},
+		Examples:    []string{"gator register paul"},
+		Handler:     handleRegister,
+	})
+	registerCommand(&Definition{
+		Name:        CmdLogin,
+		Group:       GroupUsers,
+		Description: "Login as an existing user",
+		Args:        []Arg{{Name: "username", Complete: CompleteUsers}},
+		Examples:    []string{"gator login paul"},
+		Handler:     handleLogin,
+	})
+	registerCommand(&Definition{
+		Name:        CmdUsers,
+		Group:       GroupUsers,
+		Description: "List all registered users",
+		Examples:    []string{"gator users"},
+		Handler:     handleUsers,
+	})
+
+	// Feed management
+	registerCommand(&Definition{
+		Name:        CmdAddFeed,
+		Group:       GroupFeeds,
+		Description: "Add a new RSS feed and follow it. The feed is fetched first to check it works and its posts are saved right away",
+		Args: []Arg{
+			{Name: "name", Optional: true, Usage: "name of the feed, only optional with --discover where it defaults to the feed title"},
+			{Name: "url", Usage: "url of the feed, or of the website with --discover"},
+		},
+		Flags: []Flag{
+			{Name: "discover", Kind: FlagBool, Usage: "find the feeds of a website and pick one instead of giving the feed url"},
+			{Name: "no-verify", Kind: FlagBool, Usage: "add the feed without fetching it first"},
+		},
+		Examples: []string{
+			"gator addfeed example https://example.com/feed",
+			"gator addfeed --discover https://go.dev/blog",
+		},
+		RequiresLogin: true,
+		Handler:       handleAddfeed,
+	})
+	registerCommand(&Definition{
+		Name:        CmdFeeds,
+		Group:       GroupFeeds,
+		Description: "List all available feeds",
+		Flags: []Flag{
+			{Name: "broken", Kind: FlagBool, Usage: "only show the feeds failing to fetch or disabled, with their last error"},
+		},
+		Examples: []string{"gator feeds", "gator feeds --broken"},
+		Handler:  handleFeeds,
+	})
+	registerCommand(&Definition{
+		Name:        CmdEnableFeed,
+		Group:       GroupFeeds,
+		Description: "Enable again a feed disabled after failing too many times",
+		Args:        []Arg{{Name: "url", Complete: CompleteFeeds}},
+		Examples:    []string{"gator enablefeed https://example.com/feed"},
+		Handler:     handleEnableFeed,
+	})
+	registerCommand(&Definition{
+		Name:        CmdFollow,
+		Group:       GroupFeeds,
+		Description: "Follow an existing feed",
+		Args:        []Arg{{Name: "url", Complete: CompleteFeeds}},
+		Flags: []Flag{
+			{Name: "folder", Value: "name", Usage: "put the feed in this folder", Complete: CompleteFolders},
+		},
+		Examples:      []string{"gator follow https://example.com/feed", "gator follow https://example.com/feed --folder dev"},
+		RequiresLogin: true,
+		Handler:       handleFollow,
+	})
+	registerCommand(&Definition{
+		Name:          CmdFollowing,
+		Group:         GroupFeeds,
+		Description:   "List the feeds you follow as a tree of folders, with their unread count",
+		Examples:      []string{"gator following"},
+		RequiresLogin: true,
+		Handler:       handleFollowing,
+	})
+	registerCommand(&Definition{
+		Name:        CmdFolder,
+		Group:       GroupFeeds,
+		Description: "Organize the feeds you follow in folders, dev/go is a subfolder of dev",
+		Examples:    []string{"gator folder create dev", "gator folder move https://go.dev/blog/feed.atom dev/go"},
+		Subcommands: []*Definition{
+			{
+				Name:        "create",
+				Description: "Create a folder",
+				Args:        []Arg{{Name: "name"}},
+				Examples:    []string{"gator folder create dev/go"},
+				Handler:     handleFolderCreate,
+			},
+			{
+				Name:        "list",
+				Description: "List your folders",
+				Examples:    []string{"gator folder list"},
+				Handler:     handleFolderList,
+			},
+			{
+				Name:        "rm",
+				Description: "Remove a folder without subfolders, its feeds stay followed outside of any folder",
+				Args:        []Arg{{Name: "name", Complete: CompleteFolders}},
+				Examples:    []string{"gator folder rm dev"},
+				Handler:     handleFolderRm,
+			},
+			{
+				Name:        "move",
+				Description: "Move a feed you follow to a folder, or out of any folder without a name",
+				Args: []Arg{
+					{Name: "feed-url", Complete: CompleteFollowedFeeds},
+					{Name: "name", Optional: true, Complete: CompleteFolders},
+				},
+				Examples: []string{"gator folder move https://go.dev/blog/feed.atom dev/go"},
+				Handler:  handleFolderMove,
+			},
+		},
+		RequiresLogin: true,
+	})
+	registerCommand(&Definition{
+		Name:        CmdRename,
+		Group:       GroupFeeds,
+		Description: "Show a feed you follow under your own title, only for you. Without a title the feed name is shown again",
+		Args: []Arg{
+			{Name: "url", Complete: CompleteFollowedFeeds},
+			{Name: "title", Optional: true, Variadic: true},
+		},
+		Examples:      []string{"gator rename https://news.ycombinator.com/rss HN"},
+		RequiresLogin: true,
+		Handler:       handleRename,
+	})
+	registerCommand(&Definition{
+		Name:          CmdUnfollow,
+		Group:         GroupFeeds,
+		Description:   "Unfollow a feed",
+		Args:          []Arg{{Name: "url", Complete: CompleteFollowedFeeds}},
+		Examples:      []string{"gator unfollow https://example.com/feed"},
+		RequiresLogin: true,
+		Handler:       handleUnfollow,
+	})
+	registerCommand(&Definition{
+		Name:          CmdImport,
+		Group:         GroupFeeds,
+		Description:   "Follow every feed of an OPML file, its folders become gator folders",
+		Args:          []Arg{{Name: "file.opml", Complete: CompleteFiles}},
+		Examples:      []string{"gator import subscriptions.opml"},
+		RequiresLogin: true,
+		Handler:       handleImport,
+	})
+	registerCommand(&Definition{
+		Name:          CmdExport,
+		Group:         GroupFeeds,
+		Description:   "Export the feeds you follow as OPML, to stdout if no file",
+		Args:          []Arg{{Name: "file", Optional: true, Complete: CompleteFiles}},
+		Examples:      []string{"gator export subscriptions.opml"},
+		RequiresLogin: true,
+		TextOnly:      true,
+		Handler:       handleExport,
+	})
+
+	// Content
+	registerCommand(&Definition{
+		Name:        CmdBrowse,
+		Group:       GroupContent,
+		Description: "View posts from feeds you follow, newest first",
+		Args:        []Arg{{Name: "limit", Optional: true, Usage: "number of posts to display (default: 10)"}},
+		Flags: []Flag{
+			{Name: "unread", Kind: FlagBool, Usage: "only show posts you haven't read"},
+			{Name: "folder", Value: "name", Usage: "only show posts of the feeds in this folder and its subfolders", Complete: CompleteFolders},
+			{Name: "feed", Value: "url", Usage: "only show posts of this feed", Complete: CompleteFollowedFeeds},
+			{Name: "author", Value: "name", Usage: "only show posts whose author contains this"},
+			sinceFlag,
+			untilFlag,
+			{Name: "order", Value: "asc|desc", Default: "desc", Usage: "desc for newest first, asc for oldest first", Choices: []string{"asc", "desc"}},
+			{Name: "page", Kind: FlagInt, Value: "n", Default: "1", Usage: "page of limit posts to show, starting at 1"},
+			{Name: "offset", Kind: FlagInt, Value: "n", Usage: "number of posts to skip, instead of --page"},
+		},
+		Examples: []string{
+			"gator browse 10",
+			"gator browse --unread --folder dev 10",
+			"gator browse --since 2024-01-01 --order asc --page 2 20",
+		},
+		RequiresLogin: true,
+		Handler:       handleBrowse,
+	})
+	registerCommand(&Definition{
+		Name:          CmdTui,
+		Group:         GroupContent,
+		Description:   "Full screen reader with feeds, posts and preview panes, refreshed live while agg runs",
+		Examples:      []string{"gator tui"},
+		RequiresLogin: true,
+		TextOnly:      true,
+		Handler:       handleTui,
+	})
+	registerCommand(&Definition{
+		Name:          CmdShow,
+		Group:         GroupContent,
+		Description:   "Print the full content of a post and mark it read",
+		Args:          []Arg{postIDArg},
+		Examples:      []string{"gator show 42"},
+		RequiresLogin: true,
+		Handler:       handleShow,
+	})
+	registerCommand(&Definition{
+		Name:        CmdDiff,
+		Group:       GroupContent,
+		Description: "Show what changed in a post since its previous version",
+		Args:        []Arg{postIDArg},
+		Flags: []Flag{
+			{Name: "all", Kind: FlagBool, Usage: "show every edit gator saw instead of only the last one"},
+		},
+		Examples:      []string{"gator diff 42", "gator diff --all 42"},
+		RequiresLogin: true,
+		Handler:       handleDiff,
+	})
+	registerCommand(&Definition{
+		Name:          CmdRead,
+		Group:         GroupContent,
+		Description:   "Mark a post as read",
+		Args:          []Arg{postIDArg},
+		Examples:      []string{"gator read 42"},
+		RequiresLogin: true,
+		Handler:       handleRead,
+	})
+	registerCommand(&Definition{
+		Name:          CmdMarkAllRead,
+		Group:         GroupContent,
+		Description:   "Mark all posts, or all posts of a feed, as read",
+		Args:          []Arg{{Name: "feed-url", Optional: true, Complete: CompleteFollowedFeeds}},
+		Examples:      []string{"gator mark-all-read", "gator mark-all-read https://example.com/feed"},
+		RequiresLogin: true,
+		Handler:       handleMarkAllRead,
+	})
+	registerCommand(&Definition{
+		Name:          CmdStar,
+		Group:         GroupContent,
+		Description:   "Keep a post in your reading list, starred posts are never pruned",
+		Args:          []Arg{postIDArg},
+		Examples:      []string{"gator star 42"},
+		RequiresLogin: true,
+		Handler:       handleStar,
+	})
+	registerCommand(&Definition{
+		Name:          CmdUnstar,
+		Group:         GroupContent,
+		Description:   "Remove a post from your reading list",
+		Args:          []Arg{postIDArg},
+		Examples:      []string{"gator unstar 42"},
+		RequiresLogin: true,
+		Handler:       handleUnstar,
+	})
+	registerCommand(&Definition{
+		Name:        CmdStarred,
+		Group:       GroupContent,
+		Description: "List your starred posts, or export them",
+		Args:        []Arg{{Name: "file", Optional: true, Usage: "file to export to with --export, stdout if none", Complete: CompleteFiles}},
+		Flags: []Flag{
+			{Name: "export", Value: "markdown|json", Usage: "write the starred posts as markdown or json", Choices: []string{"markdown", "json"}},
+		},
+		Examples:      []string{"gator starred", "gator starred --export markdown reading-list.md"},
+		RequiresLogin: true,
+		Handler:       handleStarred,
+	})
+	registerCommand(&Definition{
+		Name:        CmdPublish,
+		Group:       GroupContent,
+		Description: "Print the posts of the feeds a user follows as a single feed",
+		Args:        []Arg{{Name: "user", Complete: CompleteUsers}},
+		Flags: []Flag{
+			{Name: "format", Value: "atom|rss|jsonfeed", Default: "atom", Usage: "format of the feed", Choices: []string{"atom", "rss", "jsonfeed"}},
+			{Name: "limit", Kind: FlagInt, Value: "n", Default: "50", Usage: "number of posts in the feed"},
+			{Name: "link", Value: "url", Usage: "url the feed will be served from, required for rss"},
+		},
+		Examples: []string{"gator publish paul", "gator publish paul --format rss --link https://example.com/river.xml > river.xml"},
+		TextOnly: true,
+		Handler:  handlePublish,
+	})
+	registerCommand(&Definition{
+		Name:        CmdFilter,
+		Group:       GroupContent,
+		Description: "Hide posts matching a keyword or regex, or only keep the matching ones",
+		Examples:    []string{"gator filter add --field title exclude sponsored", "gator filter rm 3"},
+		Subcommands: []*Definition{
+			{
+				Name:        "add",
+				Description: "Add a filter: exclude rules hide matching posts, with include rules only matching posts are shown",
+				Args: []Arg{
+					{Name: "action", Usage: "include or exclude", Choices: []string{"include", "exclude"}},
+					{Name: "pattern", Variadic: true, Usage: "keyword, or regex with --regex (POSIX syntax of postgres, ignoring case)"},
+				},
+				Flags: []Flag{
+					{Name: "feed", Value: "url", Usage: "only apply the rule to this feed", Complete: CompleteFollowedFeeds},
+					{Name: "field", Value: "field", Default: "any", Usage: "what to match: any, title, description, author or category", Choices: filterFields},
+					{Name: "regex", Kind: FlagBool, Usage: "the pattern is a regular expression instead of a keyword"},
+				},
+				Examples: []string{
+					"gator filter add --field title exclude sponsored",
+					"gator filter add --feed https://news.ycombinator.com/rss --regex exclude '^(Ask|Show) HN'",
+				},
+				Handler: handleFilterAdd,
+			},
+			{
+				Name:        "list",
+				Description: "List your filters",
+				Examples:    []string{"gator filter list"},
+				Handler:     handleFilterList,
+			},
+			{
+				Name:        "rm",
+				Description: "Remove a filter",
+				Args:        []Arg{{Name: "id", Usage: "id of the filter, as shown by gator filter list"}},
+				Examples:    []string{"gator filter rm 3"},
+				Handler:     handleFilterRm,
+			},
+		},
+		RequiresLogin: true,
+	})
+	registerCommand(&Definition{
+		Name:        CmdSearch,
+		Group:       GroupContent,
+		Description: "Full-text search over the posts of feeds you follow, best matches first",
+		Args:        []Arg{{Name: "query", Variadic: true}},
+		Flags: []Flag{
+			{Name: "feed", Value: "url", Usage: "only search posts of this feed", Complete: CompleteFollowedFeeds},
+			sinceFlag,
+			untilFlag,
+			{Name: "limit", Kind: FlagInt, Value: "n", Default: "20", Usage: "max number of results"},
+		},
+		Examples:      []string{"gator search --since 720h golang generics"},
+		RequiresLogin: true,
+		Handler:       handleSearch,
+	})
+
+	// System
+	registerCommand(&Definition{
+		Name:        CmdAgg,
+		Group:       GroupSystem,
+		Description: "Start the aggregation, each feed is polled based on how often it posts",
+		Args: []Arg{
+			{Name: "interval", Usage: "how often to check for due feeds, feeds are never fetched more often, e.g. 1m, 1h"},
+			{Name: "concurrency", Optional: true, Usage: "number of feeds to fetch in parallel (default: 1)"},
+		},
+		Flags: []Flag{
+			{Name: "timeout", Kind: FlagDuration, Value: "duration", Default: "30s", Usage: "max time a single feed fetch can take"},
+			{Name: "prune", Kind: FlagBool, Usage: "apply the retention policy of the config after each cycle"},
+		},
+		Examples: []string{"gator agg 10m 5", "gator agg 1h --prune"},
+		TextOnly: true,
+		Handler:  handleAgg,
+	})
+	registerCommand(&Definition{
+		Name:        CmdPrune,
+		Group:       GroupSystem,
+		Description: "Remove old posts following the retention policy of the config, starred posts are kept",
+		Flags: []Flag{
+			{Name: "dry-run", Kind: FlagBool, Usage: "only report what would be removed"},
+			{Name: "max-age", Value: "duration", Usage: "remove posts older than this, e.g. 720h or 30d, instead of the config"},
+			{Name: "max-posts", Kind: FlagInt, Value: "n", Usage: "keep only this many recent posts per feed, 0 for no limit, instead of the config"},
+			{Name: "keep-unread", Kind: FlagBool, Usage: "keep posts a follower hasn't read yet, instead of the config"},
+		},
+		Examples: []string{"gator prune --dry-run", "gator prune --max-age 30d --keep-unread"},
+		Handler:  handlePrune,
+	})
+	registerCommand(&Definition{
+		Name:        CmdServe,
+		Group:       GroupSystem,
+		Description: "Serve the JSON API",
+		Args:        []Arg{{Name: "addr"}},
+		Examples:    []string{"gator serve localhost:8080"},
+		TextOnly:    true,
+		Handler:     handleServe,
+	})
+	registerCommand(&Definition{
+		Name:        CmdToken,
+		Group:       GroupSystem,
+		Description: "Manage the API tokens of the current user",
+		Examples:    []string{"gator token create laptop"},
+		Subcommands: []*Definition{
+			{
+				Name:        "create",
+				Description: "Create an API token, sent as 'Authorization: Bearer <token>'",
+				Args:        []Arg{{Name: "name"}},
+				Flags: []Flag{
+					{Name: "publish", Kind: FlagBool, Usage: "create a token for the url of your published feed, /river/<token>, giving no access to the API"},
+				},
+				Examples: []string{"gator token create laptop", "gator token create --publish feed-reader"},
+				Handler:  handleTokenCreate,
+			},
+			{
+				Name:        "list",
+				Description: "List your API tokens",
+				Examples:    []string{"gator token list"},
+				Handler:     handleTokenList,
+			},
+			{
+				Name:        "revoke",
+				Description: "Revoke an API token",
+				Args:        []Arg{{Name: "id", Usage: "id of the token, as shown by gator token list"}},
+				Examples:    []string{"gator token revoke 3"},
+				Handler:     handleTokenRevoke,
+			},
+		},
+		RequiresLogin: true,
+	})
+	registerCommand(&Definition{
+		Name:        CmdReset,
+		Group:       GroupSystem,
+		Description: "Delete all users and feeds (use with caution)",
+		Examples:    []string{"gator reset"},
+		Handler:     handleReset,
+	})
+	registerCommand(&Definition{
+		Name:        CmdCompletion,
+		Group:       GroupSystem,
+		Description: "Print the shell completion script, completing commands, flags, feed urls, folders and usernames",
+		Examples: []string{
+			"source <(gator completion bash)",
+			"gator completion fish > ~/.config/fish/completions/gator.fish",
+		},
+		Subcommands: []*Definition{
+			{
+				Name:        "bash",
+				Description: "Print the bash completion script",
+				Examples:    []string{"gator completion bash > /etc/bash_completion.d/gator"},
+				Handler:     handleCompletionBash,
+			},
+			{
+				Name:        "zsh",
+				Description: "Print the zsh completion script, compinit must be loaded first",
+				Examples:    []string{"source <(gator completion zsh)"},
+				Handler:     handleCompletionZsh,
+			},
+			{
+				Name:        "fish",
+				Description: "Print the fish completion script",
+				Examples:    []string{"gator completion fish > ~/.config/fish/completions/gator.fish"},
+				Handler:     handleCompletionFish,
+			},
+			{
+				Name:        "values",
+				Description: "Print the values the scripts complete for a kind of param, one per line",
+				Args: []Arg{{
+					Name:    "kind",
+					Usage:   "users, feeds, followed-feeds or folders",
+					Choices: []string{string(CompleteUsers), string(CompleteFeeds), string(CompleteFollowedFeeds), string(CompleteFolders)},
+				}},
+				Examples: []string{"gator completion values followed-feeds"},
+				Handler:  handleCompletionValues,
+			},
+		},
+		TextOnly: true,
+	})
+	registerCommand(&Definition{
+		Name:        CmdHelp,
+		Group:       GroupSystem,
+		Description: "Display the help, of a single command if given",
+		Args:        []Arg{{Name: "command", Optional: true, Variadic: true, Complete: CompleteCommands}},
+		Examples:    []string{"gator help", "gator help folder move"},
+		TextOnly:    true,
+		Handler:     handleHelp,
+	})
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/render"
+	"github.com/Ciobi0212/gator.git/internal/state"
+	"github.com/Ciobi0212/gator.git/internal/textdiff"
+)
+
+// diffContext is how many unchanged lines are shown around each change
+const diffContext = 2
+
+// postVersion is one state of a post, either a stored revision or the current post
+type postVersion struct {
+	seenAt      time.Time
+	title       string
+	url         string
+	description string
+	content     string
+	author      string
+	categories  []string
+	imageURL    string
+}
+
+func (v postVersion) lines() []string {
+	body := v.content
+	if strings.TrimSpace(body) == "" {
+		body = v.description
+	}
+
+	// Every field of the content hash is shown, a change to any of them is a revision
+	header := "Title: " + v.title + "\nLink: " + v.url
+	if v.author != "" {
+		header += "\nAuthor: " + v.author
+	}
+	if len(v.categories) > 0 {
+		header += "\nCategories: " + strings.Join(v.categories, ", ")
+	}
+	if v.imageURL != "" {
+		header += "\nImage: " + v.imageURL
+	}
+
+	text := header + "\n\n" + render.HTMLToText(body, defaultShowWidth)
+	return strings.Split(text, "\n")
+}
+
+func handleDiff(ctx context.Context, state *state.AppState, in *Input) error {
+	postID, err := strconv.Atoi(in.Arg("post-id"))
+	if err != nil {
+		return NewUserFacingError("post id is not a number", "use gator browse to see the ids of the posts")
+	}
+
+	post, err := state.Db.FindPostForUser(
+		ctx,
+		database.FindPostForUserParams{
+			UserID: in.User.ID,
+			ID:     int32(postID),
+		},
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewUserFacingError("no post with this id in the feeds you follow", "use gator browse to see the ids of the posts")
+		}
+		return fmt.Errorf("err finding post: %w", err)
+	}
+
+	revisions, err := state.Db.GetPostRevisions(ctx, post.ID)
+	if err != nil {
+		return fmt.Errorf("err getting post revisions: %w", err)
+	}
+
+	if len(revisions) == 0 {
+		state.Out.Info("'%s' hasn't changed since gator first saw it\n", post.Title)
+		return state.Out.List([]diffRecord{}, func() {})
+	}
+
+	versions := make([]postVersion, 0, len(revisions)+1)
+	for _, revision := range revisions {
+		versions = append(versions, postVersion{
+			seenAt:      revision.CreatedAt,
+			title:       revision.Title,
+			url:         revision.Url,
+			description: revision.Description,
+			content:     revision.Content,
+			author:      revision.Author,
+			categories:  revision.Categories,
+			imageURL:    revision.ImageUrl,
+		})
+	}
+	versions = append(versions, postVersion{
+		seenAt:      post.UpdatedAt,
+		title:       post.Title,
+		url:         post.Url,
+		description: post.Description,
+		content:     post.Content,
+		author:      post.Author,
+		categories:  post.Categories,
+		imageURL:    post.ImageUrl,
+	})
+
+	state.Out.Info("'%s' changed %d times\n", post.Title, len(revisions))
+
+	if !in.Bool("all") {
+		versions = versions[len(versions)-2:]
+	}
+
+	if state.Out.IsText() {
+		for i := 1; i < len(versions); i++ {
+			before, after := versions[i-1], versions[i]
+
+			fmt.Println()
+			fmt.Printf("--- %s\n", before.seenAt.Format(time.DateTime))
+			fmt.Printf("+++ %s\n", after.seenAt.Format(time.DateTime))
+			fmt.Print(textdiff.Format(textdiff.Lines(before.lines(), after.lines()), diffContext))
+		}
+		return nil
+	}
+
+	// Records only hold the changed lines, the context is for reading
+	var records []diffRecord
+	for i := 1; i < len(versions); i++ {
+		before, after := versions[i-1], versions[i]
+
+		for _, line := range textdiff.Lines(before.lines(), after.lines()) {
+			if line.Op == textdiff.Equal {
+				continue
+			}
+
+			change := "added"
+			if line.Op == textdiff.Delete {
+				change = "removed"
+			}
+
+			records = append(records, diffRecord{
+				PostID: post.ID,
+				From:   before.seenAt,
+				To:     after.seenAt,
+				Change: change,
+				Text:   line.Text,
+			})
+		}
+	}
+
+	return state.Out.List(records, nil)
+}
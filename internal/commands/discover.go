@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/requests"
+	"github.com/Ciobi0212/gator.git/internal/state"
+	"github.com/mmcdole/gofeed"
+)
+
+// discoverTimeout bounds the whole discovery, which may try several urls of the site
+const discoverTimeout = time.Minute
+
+// discoverFeed finds the feeds of a website and lets the user pick one when there are several
+func discoverFeed(ctx context.Context, state *state.AppState, siteURL string) (requests.Candidate, error) {
+	ctx, cancel := context.WithTimeout(ctx, discoverTimeout)
+	defer cancel()
+
+	state.Out.Info("Looking for feeds on %s...\n", siteURL)
+
+	candidates, err := requests.DiscoverFeeds(ctx, siteURL)
+	if err != nil {
+		return requests.Candidate{}, NewUserFacingError("can't load "+siteURL+": "+err.Error(), "check the address of the website")
+	}
+
+	if len(candidates) == 0 {
+		return requests.Candidate{}, NewUserFacingError("no feed found on "+siteURL, "if you know the feed url, add it directly: gator addfeed <name> <url>")
+	}
+
+	if len(candidates) == 1 {
+		state.Out.Info("Found %s\n", describeCandidate(candidates[0]))
+		return candidates[0], nil
+	}
+
+	state.Out.Info("Found %d feeds:\n", len(candidates))
+	for i, candidate := range candidates {
+		state.Out.Info("  %d. %s\n", i+1, describeCandidate(candidate))
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		state.Out.Info("Which one do you want to add? [1-%d]: ", len(candidates))
+
+		line, err := reader.ReadString('\n')
+		if err != nil && strings.TrimSpace(line) == "" {
+			return requests.Candidate{}, NewUserFacingError("no feed picked", "run the command again in a terminal, or add the feed url directly")
+		}
+
+		choice, err := strconv.Atoi(strings.TrimSpace(line))
+		if err == nil && choice >= 1 && choice <= len(candidates) {
+			return candidates[choice-1], nil
+		}
+
+		state.Out.Info("Please answer with the number of a feed\n")
+	}
+}
+
+func describeCandidate(candidate requests.Candidate) string {
+	if candidate.Title == "" {
+		return candidate.URL
+	}
+	return fmt.Sprintf("%s (%s)", candidate.Title, candidate.URL)
+}
+
+// feedName is the name to give a discovered feed when the user didn't choose one:
+// the title of the feed itself when it was fetched, else the title of the link announcing it, else the host
+func feedName(candidate requests.Candidate, result *requests.FetchResult) string {
+	if result != nil && strings.TrimSpace(result.Feed.Title) != "" {
+		return strings.TrimSpace(result.Feed.Title)
+	}
+
+	if candidate.Title != "" {
+		return candidate.Title
+	}
+
+	u, err := url.Parse(candidate.URL)
+	if err == nil && u.Host != "" {
+		return u.Host
+	}
+
+	return candidate.URL
+}
+
+// verifyTimeout bounds the test fetch of a feed being added
+const verifyTimeout = 30 * time.Second
+
+// verifyFeed fetches a feed before it is added, so typos and web pages are caught right away
+func verifyFeed(ctx context.Context, state *state.AppState, feedURL string) (*requests.FetchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+
+	result, err := requests.FetchFeed(ctx, feedURL, requests.Validators{})
+	if err != nil {
+		var statusErr *requests.StatusError
+		if errors.As(err, &statusErr) {
+			return nil, NewUserFacingError("can't fetch "+feedURL+": "+statusErr.Status, "check the url, or add it anyway with --no-verify")
+		}
+		if errors.Is(err, gofeed.ErrFeedTypeNotDetected) {
+			return nil, NewUserFacingError(feedURL+" is not an RSS, Atom or JSON feed", "to find the feed of a website, use gator addfeed --discover <site-url>")
+		}
+		return nil, NewUserFacingError("can't fetch "+feedURL+": "+err.Error(), "check the url, or add it anyway with --no-verify")
+	}
+
+	feed := result.Feed
+
+	var newest time.Time
+	for _, item := range feed.Items {
+		if item.PublishedParsed != nil && item.PublishedParsed.After(newest) {
+			newest = *item.PublishedParsed
+		}
+		if item.UpdatedParsed != nil && item.UpdatedParsed.After(newest) {
+			newest = *item.UpdatedParsed
+		}
+	}
+
+	state.Out.Info("Found %s feed '%s' with %d items", feedFormat(feed.FeedType, feed.FeedVersion), feed.Title, len(feed.Items))
+	if !newest.IsZero() {
+		state.Out.Info(", newest from %s", newest.Format(time.DateOnly))
+	}
+	state.Out.Info("\n")
+
+	return result, nil
+}
+
+func feedFormat(feedType string, version string) string {
+	name := feedType
+
+	switch feedType {
+	case "rss":
+		name = "RSS"
+	case "atom":
+		name = "Atom"
+	case "json":
+		name = "JSON Feed"
+	}
+
+	if version == "" {
+		return name
+	}
+	return name + " " + version
+}
+
+// seedFeed saves the posts of the test fetch of a new feed, so they can be read without waiting for agg,
+// and records the fetch as agg would so agg next fetches the feed when it's due
+func seedFeed(ctx context.Context, state *state.AppState, feed database.Feed, result *requests.FetchResult) error {
+	err := state.Db.MarkFeedFetched(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("err marking feed fetched: %w", err)
+	}
+
+	err = state.Db.RecordFeedSuccess(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("err recording success of feed: %w", err)
+	}
+
+	err = state.Db.SetFeedValidators(
+		ctx,
+		database.SetFeedValidatorsParams{
+			ID:           feed.ID,
+			Etag:         sql.NullString{String: result.Validators.ETag, Valid: result.Validators.ETag != ""},
+			LastModified: sql.NullString{String: result.Validators.LastModified, Valid: result.Validators.LastModified != ""},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err saving validators of feed: %w", err)
+	}
+
+	created, _ := savePosts(ctx, state, feed, result.Feed.Items)
+	state.Out.Info("Saved %d posts from %s\n", created, feed.Name)
+
+	// agg raises the interval to its own when it fetches the feed next
+	scheduleNextFetch(ctx, state, feed, result.Hints, 0)
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/state"
+	"github.com/lib/pq"
+)
+
+// invalidRegexCode is the postgres error code for an invalid regular expression
+const invalidRegexCode = "2201B"
+
+var filterFields = []string{"any", "title", "description", "author", "category"}
+
+func handleFilterAdd(ctx context.Context, state *state.AppState, in *Input) error {
+	action, pattern := in.Arg("action"), in.Arg("pattern")
+	feedURL, field := in.String("feed"), in.String("field")
+
+	matchType := "keyword"
+	if in.Bool("regex") {
+		matchType = "regex"
+
+		// The rule is evaluated by postgres, so its regex flavor is the one that counts
+		err := state.Db.CheckRegex(ctx, pattern)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == invalidRegexCode {
+				return NewUserFacingError("invalid regex: "+pqErr.Message, "regexes use the POSIX syntax of postgres and ignore case")
+			}
+			return fmt.Errorf("err checking regex: %w", err)
+		}
+	}
+
+	var feedID sql.NullInt32
+
+	if feedURL != "" {
+		feed, err := state.Db.FindFeedByURL(ctx, feedURL)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return NewUserFacingError("no feed with specified url exists in your db", "use gator following to see the feeds you are following")
+			}
+			return fmt.Errorf("err finding feed: %w", err)
+		}
+		feedID = sql.NullInt32{Int32: feed.ID, Valid: true}
+	}
+
+	rule, err := state.Db.CreateFilterRule(
+		ctx,
+		database.CreateFilterRuleParams{
+			CreatedAt: time.Now().UTC(),
+			UserID:    in.User.ID,
+			FeedID:    feedID,
+			Action:    action,
+			Field:     field,
+			MatchType: matchType,
+			Pattern:   pattern,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err creating filter rule: %w", err)
+	}
+
+	record := filterRecord{
+		ID:        rule.ID,
+		Action:    rule.Action,
+		Field:     rule.Field,
+		MatchType: rule.MatchType,
+		Pattern:   rule.Pattern,
+		Feed:      feedURL,
+	}
+
+	return state.Out.Item(record, func() {
+		fmt.Printf("Added filter %d\n", rule.ID)
+	})
+}
+
+func handleFilterList(ctx context.Context, state *state.AppState, in *Input) error {
+	rules, err := state.Db.GetFilterRulesForUser(ctx, in.User.ID)
+	if err != nil {
+		return fmt.Errorf("err getting filter rules: %w", err)
+	}
+
+	records := make([]filterRecord, 0, len(rules))
+	for _, rule := range rules {
+		records = append(records, filterRecord{
+			ID:        rule.ID,
+			Action:    rule.Action,
+			Field:     rule.Field,
+			MatchType: rule.MatchType,
+			Pattern:   rule.Pattern,
+			Feed:      rule.FeedUrl.String,
+		})
+	}
+
+	return state.Out.List(records, func() {
+		if len(rules) == 0 {
+			fmt.Println("No filters, every post of the feeds you follow is shown")
+			return
+		}
+
+		for _, rule := range records {
+			scope := "all feeds"
+			if rule.Feed != "" {
+				scope = rule.Feed
+			}
+
+			fmt.Printf("%d  %s  %s %s %q  (%s)\n", rule.ID, rule.Action, rule.Field, rule.MatchType, rule.Pattern, scope)
+		}
+	})
+}
+
+func handleFilterRm(ctx context.Context, state *state.AppState, in *Input) error {
+	id, err := strconv.Atoi(in.Arg("id"))
+	if err != nil {
+		return NewUserFacingError("filter id is not a number", "use gator filter list to see your filters")
+	}
+
+	deleted, err := state.Db.DeleteFilterRule(
+		ctx,
+		database.DeleteFilterRuleParams{
+			ID:     int32(id),
+			UserID: in.User.ID,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err deleting filter rule: %w", err)
+	}
+
+	if deleted == 0 {
+		return NewUserFacingError("you have no filter with this id", "use gator filter list to see your filters")
+	}
+
+	state.Out.Info("Filter removed\n")
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseFlags parses params with fs and returns the positional params.
+// Unlike fs.Parse, flags can be placed before, between or after the positional params.
+// Everything after -- is positional, e.g. a filter pattern starting with a dash.
+func parseFlags(fs *flag.FlagSet, params []string) ([]string, error) {
+	var positional []string
+
+	params, rest := splitTerminator(fs, params)
+
+	for {
+		err := fs.Parse(params)
+		if err != nil {
+			return nil, err
+		}
+
+		params = fs.Args()
+		if len(params) == 0 {
+			return append(positional, rest...), nil
+		}
+
+		positional = append(positional, params[0])
+		params = params[1:]
+	}
+}
+
+// splitTerminator splits params at the -- ending the flags. A -- given as the value of a flag is not one.
+func splitTerminator(fs *flag.FlagSet, params []string) ([]string, []string) {
+	for i := 0; i < len(params); i++ {
+		param := params[i]
+
+		if param == "--" {
+			return params[:i], params[i+1:]
+		}
+
+		if !strings.HasPrefix(param, "-") || strings.Contains(param, "=") {
+			continue
+		}
+
+		// A flag taking a value takes the next param, whatever it is
+		f := fs.Lookup(strings.TrimLeft(param, "-"))
+		if f == nil {
+			continue
+		}
+		if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && boolFlag.IsBoolFlag() {
+			continue
+		}
+		i++
+	}
+
+	return params, nil
+}
+
+// parseSince and parseUntil read --since and --until: either a date (2006-01-02 or RFC3339) or a
+// duration (see parseDuration), in which case the time is that long ago. Until is exclusive and a
+// date alone covers its whole day, so --until 2024-01-31 keeps the posts of the 31st.
+func parseSince(value string) (time.Time, error) {
+	return parseTimeParam(value, false)
+}
+
+func parseUntil(value string) (time.Time, error) {
+	return parseTimeParam(value, true)
+}
+
+func parseTimeParam(value string, until bool) (time.Time, error) {
+	t, err := time.Parse(time.DateOnly, value)
+	if err == nil {
+		if until {
+			return t.AddDate(0, 0, 1), nil
+		}
+		return t, nil
+	}
+
+	t, err = time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t.UTC(), nil
+	}
+
+	d, err := parseDuration(value)
+	if err == nil {
+		return time.Now().UTC().Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("'%s' is neither a date nor a duration", value)
+}
+
+// parseDuration is time.ParseDuration with days (e.g. 30d), as ages are rarely counted in hours.
+// It is the grammar of every duration going back in time: --since, --until and --max-age.
+func parseDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("'%s' is not a number of days", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("'%s' is not a duration", value)
+	}
+
+	return age, nil
+}
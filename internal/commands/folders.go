@@ -0,0 +1,266 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+func handleFolderCreate(ctx context.Context, state *state.AppState, in *Input) error {
+	name, err := folderName(in.Arg("name"))
+	if err != nil {
+		return err
+	}
+
+	_, err = state.Db.FindFolderByName(ctx, database.FindFolderByNameParams{UserID: in.User.ID, Name: name})
+	if err == nil {
+		return NewUserFacingError("you already have a folder named "+name, "use gator folder list to see your folders")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("err finding folder: %w", err)
+	}
+
+	folder, err := state.Db.CreateFolder(
+		ctx,
+		database.CreateFolderParams{
+			CreatedAt: time.Now().UTC(),
+			UserID:    in.User.ID,
+			Name:      name,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err creating folder: %w", err)
+	}
+
+	return state.Out.Item(folderRecord{Name: folder.Name, CreatedAt: folder.CreatedAt}, func() {
+		fmt.Printf("Folder %s created, add feeds to it with gator follow <url> --folder %s\n", name, name)
+	})
+}
+
+func handleFolderList(ctx context.Context, state *state.AppState, in *Input) error {
+	folders, err := state.Db.GetFoldersForUser(ctx, in.User.ID)
+	if err != nil {
+		return fmt.Errorf("err getting folders: %w", err)
+	}
+
+	records := make([]folderRecord, 0, len(folders))
+	for _, folder := range folders {
+		records = append(records, folderRecord{Name: folder.Name, CreatedAt: folder.CreatedAt})
+	}
+
+	return state.Out.List(records, func() {
+		if len(folders) == 0 {
+			fmt.Println("No folders yet, create one with gator folder create <name>")
+			return
+		}
+
+		for _, folder := range folders {
+			fmt.Println(folder.Name)
+		}
+	})
+}
+
+func handleFolderRm(ctx context.Context, state *state.AppState, in *Input) error {
+	name, err := folderName(in.Arg("name"))
+	if err != nil {
+		return err
+	}
+
+	// Removing dev would leave dev/go without a parent, so subfolders go first
+	folders, err := state.Db.GetFoldersForUser(ctx, in.User.ID)
+	if err != nil {
+		return fmt.Errorf("err getting folders: %w", err)
+	}
+
+	for _, folder := range folders {
+		if strings.HasPrefix(folder.Name, name+"/") {
+			return NewUserFacingError("folder "+name+" has subfolders, like "+folder.Name, "remove them first with gator folder rm "+folder.Name)
+		}
+	}
+
+	deleted, err := state.Db.DeleteFolder(ctx, database.DeleteFolderParams{UserID: in.User.ID, Name: name})
+	if err != nil {
+		return fmt.Errorf("err deleting folder: %w", err)
+	}
+
+	if deleted == 0 {
+		return NewUserFacingError("you have no folder named "+name, "use gator folder list to see your folders")
+	}
+
+	state.Out.Info("Folder removed, its feeds are still followed outside of any folder\n")
+
+	return nil
+}
+
+func handleFolderMove(ctx context.Context, state *state.AppState, in *Input) error {
+	feed, err := state.Db.FindFeedByURL(ctx, in.Arg("feed-url"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewUserFacingError("no feed with specified url exists in your db", "use gator following to see the feeds you are following")
+		}
+		return fmt.Errorf("err finding feed: %w", err)
+	}
+
+	// Without a folder name the feed is taken out of its folder
+	var folderID sql.NullInt32
+	if in.Arg("name") != "" {
+		name, err := folderName(in.Arg("name"))
+		if err != nil {
+			return err
+		}
+
+		folderID, err = findFolder(ctx, state, in.User, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	updated, err := state.Db.SetFeedFollowFolder(
+		ctx,
+		database.SetFeedFollowFolderParams{
+			UserID:    in.User.ID,
+			FeedID:    feed.ID,
+			FolderID:  folderID,
+			UpdatedAt: time.Now().UTC(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err moving feed: %w", err)
+	}
+
+	if updated == 0 {
+		return NewUserFacingError("you don't follow this feed", "use gator follow <url> --folder <name> to follow it")
+	}
+
+	state.Out.Info("Moved %s\n", feed.Name)
+
+	return nil
+}
+
+// folderName cleans up a folder path, "/dev//go/" becomes "dev/go"
+func folderName(name string) (string, error) {
+	var parts []string
+	for _, part := range strings.Split(name, "/") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	if len(parts) == 0 {
+		return "", NewUserFacingError("folder name can't be empty", "e.g: gator folder create dev, or dev/go for a subfolder")
+	}
+
+	return strings.Join(parts, "/"), nil
+}
+
+// findFolder returns the id of an existing folder of user, for the folder_id of a follow
+func findFolder(ctx context.Context, state *state.AppState, user database.User, name string) (sql.NullInt32, error) {
+	folder, err := state.Db.FindFolderByName(ctx, database.FindFolderByNameParams{UserID: user.ID, Name: name})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sql.NullInt32{}, NewUserFacingError("you have no folder named "+name, "create it with gator folder create "+name)
+		}
+		return sql.NullInt32{}, fmt.Errorf("err finding folder: %w", err)
+	}
+
+	return sql.NullInt32{Int32: folder.ID, Valid: true}, nil
+}
+
+// checkFolderPath fails when user has no folder at path, nor below it: dev only exists as the
+// parent of dev/go when only dev/go was created, and browse --folder dev shows its posts
+func checkFolderPath(ctx context.Context, state *state.AppState, user database.User, path string) error {
+	folders, err := state.Db.GetFoldersForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("err getting folders: %w", err)
+	}
+
+	for _, folder := range folders {
+		if folder.Name == path || strings.HasPrefix(folder.Name, path+"/") {
+			return nil
+		}
+	}
+
+	return NewUserFacingError("you have no folder named "+path, "use gator folder list to see your folders")
+}
+
+type folderNode struct {
+	name     string
+	children map[string]*folderNode
+	follows  []database.GetFeedFollowsForUserRow
+}
+
+func newFolderNode(name string) *folderNode {
+	return &folderNode{name: name, children: make(map[string]*folderNode)}
+}
+
+// child returns the node of a folder path below n, creating the missing ones
+func (n *folderNode) child(path string) *folderNode {
+	node := n
+	for _, part := range strings.Split(path, "/") {
+		next, ok := node.children[part]
+		if !ok {
+			next = newFolderNode(part)
+			node.children[part] = next
+		}
+		node = next
+	}
+	return node
+}
+
+// printFollowingTree prints the folders of the user and the feeds they contain as a tree,
+// feeds outside of any folder come last
+func printFollowingTree(follows []database.GetFeedFollowsForUserRow, folders []database.Folder) {
+	root := newFolderNode("")
+
+	// Empty folders are shown too
+	for _, folder := range folders {
+		root.child(folder.Name)
+	}
+
+	for _, follow := range follows {
+		node := root
+		if follow.Folder.Valid {
+			node = root.child(follow.Folder.String)
+		}
+		node.follows = append(node.follows, follow)
+	}
+
+	root.print("")
+}
+
+func (n *folderNode) print(indent string) {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	total := len(names) + len(n.follows)
+	i := 0
+
+	branch := func() (string, string) {
+		i++
+		if i == total {
+			return indent + "└── ", indent + "    "
+		}
+		return indent + "├── ", indent + "│   "
+	}
+
+	for _, name := range names {
+		prefix, childIndent := branch()
+		fmt.Printf("%s%s/\n", prefix, name)
+		n.children[name].print(childIndent)
+	}
+
+	for _, follow := range n.follows {
+		prefix, _ := branch()
+		fmt.Printf("%s%s  %s  (%d unread)\n", prefix, follow.Name, follow.Url, follow.UnreadCount)
+	}
+}
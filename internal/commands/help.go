@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+// usageWidth aligns the descriptions of the command list
+const usageWidth = 24
+
+func handleHelp(ctx context.Context, state *state.AppState, in *Input) error {
+	if len(in.Args) == 0 {
+		printHelp()
+		return nil
+	}
+
+	def, ok := mapCommands[in.Args[0]]
+	if !ok {
+		return NewUserFacingError("unknown command "+in.Args[0], "use gator help to see the available commands")
+	}
+
+	for _, name := range in.Args[1:] {
+		sub := def.subcommand(name)
+		if sub == nil {
+			return NewUserFacingError("unknown subcommand "+def.Path()+" "+name, "use gator help "+def.Path()+" to see its subcommands")
+		}
+		def = sub
+	}
+
+	return printCommandHelp(def)
+}
+
+func isHelpFlag(param string) bool {
+	return param == "-h" || param == "-help" || param == "--help"
+}
+
+// printHelp prints the overview of gator and the list of commands, grouped as in the README
+func printHelp() {
+	fmt.Println("Gator - RSS Feed Aggregator")
+	fmt.Println("===========================")
+	fmt.Println()
+
+	// App description
+	fmt.Println("ABOUT:")
+	fmt.Println("  Gator is a command-line RSS feed aggregator that helps you follow")
+	fmt.Println("  and discover content from your favorite websites using RSS feeds.")
+	fmt.Println("  It allows you to register as a user, subscribe to multiple feeds,")
+	fmt.Println("  and browse the latest posts all from your terminal.")
+	fmt.Println()
+
+	fmt.Println("HOW IT WORKS:")
+	fmt.Println("  1. Register or login to your account")
+	fmt.Println("  2. Add or follow RSS feeds you're interested in")
+	fmt.Println("  3. Start the aggregator to fetch the latest content")
+	fmt.Println("  4. Browse posts from your followed feeds")
+	fmt.Println()
+
+	fmt.Println("WORKFLOW EXAMPLE:")
+	fmt.Println("  ./gator register john         # Create a user account")
+	fmt.Println("  ./gator addfeed 'Tech News' https://example.com/rss  # Add a feed")
+	fmt.Println("  ./gator agg 10m 5 &             # Start aggregation in background (every 10 min) with 5 concurrent scrapers")
+	fmt.Println("  ./gator browse 20             # View the 20 most recent posts")
+	fmt.Println()
+
+	fmt.Println("GLOBAL OPTIONS:")
+	fmt.Println("  --output text|json|ndjson|csv|tsv  - Print the results of any command for scripts instead of for reading")
+	fmt.Println("                              (default: text), messages then go to stderr. Not for agg, serve, tui, export, publish")
+	fmt.Println()
+
+	fmt.Println("AVAILABLE COMMANDS:")
+
+	for _, group := range groups {
+		fmt.Println()
+		fmt.Printf("%s:\n", group)
+
+		for _, def := range definitions {
+			if def.Group != group {
+				continue
+			}
+
+			if len(def.Subcommands) == 0 {
+				printCommandLine(def)
+				continue
+			}
+
+			for _, sub := range def.Subcommands {
+				printCommandLine(sub)
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("Run ./gator help <command> to see the params, flags and examples of a command.")
+}
+
+func printCommandLine(def *Definition) {
+	description := def.Description
+	if def.RequiresLogin {
+		description += " (requires login)"
+	}
+
+	// Long usages get their own line so the descriptions stay in one column
+	usage := def.Usage()
+	if len(usage) > usageWidth {
+		fmt.Printf("  %s\n", usage)
+		usage = ""
+	}
+
+	fmt.Printf("  %-*s - %s\n", usageWidth, usage, description)
+}
+
+// printCommandHelp prints everything the definition of a command says about it
+func printCommandHelp(def *Definition) error {
+	fmt.Printf("Usage: gator %s\n", def.Usage())
+	fmt.Println()
+	fmt.Println(def.Description)
+
+	if def.RequiresLogin {
+		fmt.Println("Requires login, see gator login.")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+
+	if len(def.Subcommands) > 0 {
+		fmt.Fprintln(w, "\nSubcommands:")
+		for _, sub := range def.Subcommands {
+			fmt.Fprintf(w, "  %s\t%s\n", strings.TrimPrefix(sub.Usage(), def.Path()+" "), sub.Description)
+		}
+	}
+
+	if len(def.Args) > 0 {
+		fmt.Fprintln(w, "\nArguments:")
+		for _, arg := range def.Args {
+			fmt.Fprintf(w, "  %s\t%s\n", arg.Name, arg.Usage)
+		}
+	}
+
+	if len(def.Flags) > 0 {
+		fmt.Fprintln(w, "\nFlags:")
+		for _, f := range def.Flags {
+			usage := f.Usage
+			if f.Default != "" && f.Kind != FlagBool {
+				usage += " (default: " + f.Default + ")"
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", f.usage(), usage)
+		}
+	}
+
+	if len(def.Examples) > 0 {
+		fmt.Fprintln(w, "\nExamples:")
+		for _, example := range def.Examples {
+			fmt.Fprintf(w, "  %s\n", example)
+		}
+	}
+
+	return w.Flush()
+}
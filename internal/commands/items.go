@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/dedup"
+	"github.com/Ciobi0212/gator.git/internal/state"
+	"github.com/mmcdole/gofeed"
+)
+
+// savePosts inserts the new items of a feed and updates the ones whose content changed.
+// items is the whole feed, the pruned posts it no longer lists are forgotten.
+func savePosts(ctx context.Context, state *state.AppState, feed database.Feed, items []*gofeed.Item) (created int, updated int) {
+	for _, item := range items {
+		publishedAt, err := parseFeedTime(item.Published)
+		if err != nil {
+			state.Out.Info("error parsing PubDate: %v\n", err)
+			publishedAt = time.Time{}
+		}
+
+		// Posts are unique per feed on their guid (or normalized url), known posts are only
+		// updated when their content changed, otherwise nothing is returned (see posts.sql)
+		post, err := state.Db.UpsertPost(ctx, newPostParams(feed.ID, item, publishedAt))
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			state.Out.Error(fmt.Errorf("err saving post %s: %w", item.Title, err))
+			continue
+		}
+
+		switch {
+		case post.Inserted:
+			created++
+		case post.Revised:
+			updated++
+		default:
+			// Saved before content hashes, the post only got its hash and nothing changed for readers
+			continue
+		}
+
+		err = saveEnclosures(ctx, state, post.ID, item, post.Revised)
+		if err != nil {
+			state.Out.Error(fmt.Errorf("err saving enclosures of post %s: %w", item.Title, err))
+		}
+	}
+
+	// An empty list is more likely a broken fetch than a feed dropping all its posts
+	if len(items) > 0 {
+		keys := make([]string, 0, len(items))
+		for _, item := range items {
+			keys = append(keys, dedup.Key(item.GUID, item.Link))
+		}
+
+		err := state.Db.ForgetPrunedPosts(ctx, database.ForgetPrunedPostsParams{FeedID: feed.ID, Keys: keys})
+		if err != nil {
+			state.Out.Error(fmt.Errorf("err forgetting pruned posts of %s: %w", feed.Name, err))
+		}
+	}
+
+	return created, updated
+}
+
+// rekeyBatch is how many legacy posts are rekeyed per query
+const rekeyBatch = 500
+
+// rekeyLegacyPosts gives the posts saved before deduplication the key dedup.Key computes for them,
+// so fetching them again updates them instead of inserting them twice. A post getting the key of
+// another post of its feed is a duplicate, it's removed and its stars and reads go to the other
+// post. Nothing is left to do once it ran, and running it twice at once is safe.
+func rekeyLegacyPosts(ctx context.Context, state *state.AppState) error {
+	rekeyed, removed := 0, 0
+
+	for {
+		posts, err := state.Db.GetLegacyKeyPosts(ctx, rekeyBatch)
+		if err != nil {
+			return fmt.Errorf("err exec query get legacy key posts: %w", err)
+		}
+		if len(posts) == 0 {
+			break
+		}
+
+		for _, post := range posts {
+			updated, err := state.Db.SetPostDedupKey(ctx, database.SetPostDedupKeyParams{
+				ID:       post.ID,
+				DedupKey: dedup.Key(post.Guid, post.Url),
+			})
+			if err != nil {
+				return fmt.Errorf("err exec query set post dedup key: %w", err)
+			}
+			if updated > 0 {
+				rekeyed++
+				continue
+			}
+
+			merged, err := state.Db.MergeLegacyPost(ctx, database.MergeLegacyPostParams{
+				ID:       post.ID,
+				DedupKey: dedup.Key(post.Guid, post.Url),
+			})
+			if err != nil {
+				return fmt.Errorf("err exec query merge legacy post: %w", err)
+			}
+			removed += int(merged)
+		}
+	}
+
+	if rekeyed+removed > 0 {
+		state.Out.Info("Rekeyed %d posts saved before deduplication and removed %d duplicates\n", rekeyed, removed)
+	}
+
+	return nil
+}
+
+// newPostParams maps a feed item to the post stored for it
+func newPostParams(feedID int32, item *gofeed.Item, publishedAt time.Time) database.UpsertPostParams {
+	authors := make([]string, 0, len(item.Authors))
+	for _, author := range item.Authors {
+		if author != nil && author.Name != "" {
+			authors = append(authors, author.Name)
+		}
+	}
+
+	// A nil slice would be stored as NULL, the column expects an empty array
+	categories := item.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+
+	imageURL := ""
+	if item.Image != nil {
+		imageURL = item.Image.URL
+	}
+
+	params := database.UpsertPostParams{
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+		Title:       item.Title,
+		Url:         item.Link,
+		Description: item.Description,
+		PublishedAt: publishedAt,
+		FeedID:      feedID,
+		Content:     item.Content,
+		Author:      strings.Join(authors, ", "),
+		Categories:  categories,
+		Guid:        item.GUID,
+		ImageUrl:    imageURL,
+		DedupKey:    dedup.Key(item.GUID, item.Link),
+	}
+	params.ContentHash = contentHash(params)
+
+	return params
+}
+
+// contentHash fingerprints what a publisher can edit in a post, a new hash means a new revision
+func contentHash(post database.UpsertPostParams) string {
+	fields := []string{
+		post.Title,
+		post.Url,
+		post.Description,
+		post.Content,
+		post.Author,
+		strings.Join(post.Categories, "\x1f"),
+		post.ImageUrl,
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// saveEnclosures stores the attachments (podcast episodes, images...) of a post,
+// replacing the previous ones when the post was updated
+func saveEnclosures(ctx context.Context, state *state.AppState, postID int32, item *gofeed.Item, replace bool) error {
+	if replace {
+		err := state.Db.DeletePostEnclosures(ctx, postID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, enclosure := range item.Enclosures {
+		if enclosure == nil || enclosure.URL == "" {
+			continue
+		}
+
+		// Length is optional and often wrong, 0 means unknown
+		length, _ := strconv.ParseInt(enclosure.Length, 10, 64)
+
+		err := state.Db.CreatePostEnclosure(
+			ctx,
+			database.CreatePostEnclosureParams{
+				PostID: postID,
+				Url:    enclosure.URL,
+				Type:   enclosure.Type,
+				Length: length,
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/opml"
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+func handleImport(ctx context.Context, state *state.AppState, in *Input) error {
+	f, err := os.Open(in.Arg("file.opml"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return NewUserFacingError("file "+in.Arg("file.opml")+" does not exist", "check the path of the opml file")
+		}
+		return fmt.Errorf("err opening opml file: %w", err)
+	}
+	defer f.Close()
+
+	doc, err := opml.Parse(f)
+	if err != nil {
+		return NewUserFacingError("file is not a valid opml document", "export your subscriptions as OPML from your previous reader")
+	}
+
+	follows, err := state.Db.GetFeedFollowsForUser(ctx, in.User.ID)
+	if err != nil {
+		return fmt.Errorf("err getting feeds for user: %w", err)
+	}
+
+	alreadyFollowing := make(map[string]bool)
+	for _, follow := range follows {
+		alreadyFollowing[follow.Url] = true
+	}
+
+	created, followed, skipped := 0, 0, 0
+	renamed := make(map[string]string)
+
+	for _, sub := range doc.Subscriptions() {
+		maps.Copy(renamed, sub.Renamed)
+
+		if alreadyFollowing[sub.URL] {
+			skipped++
+			continue
+		}
+
+		feed, err := state.Db.FindFeedByURL(ctx, sub.URL)
+		if errors.Is(err, sql.ErrNoRows) {
+			feed, err = state.Db.CreateFeed(
+				ctx,
+				database.CreateFeedParams{
+					Name:      sub.Name,
+					Url:       sub.URL,
+					CreatedAt: time.Now().UTC(),
+					UpdatedAt: time.Now().UTC(),
+				},
+			)
+			if err != nil {
+				return fmt.Errorf("err creating feed %s: %w", sub.URL, err)
+			}
+			created++
+		} else if err != nil {
+			return fmt.Errorf("err finding feed %s: %w", sub.URL, err)
+		}
+
+		// OPML folders become gator folders, created on the fly. Folders without a name are
+		// skipped, like in the paths given to gator folder create.
+		var folderID sql.NullInt32
+		if name, err := folderName(sub.Category); err == nil {
+			folder, err := state.Db.EnsureFolder(
+				ctx,
+				database.EnsureFolderParams{
+					CreatedAt: time.Now().UTC(),
+					UserID:    in.User.ID,
+					Name:      name,
+				},
+			)
+			if err != nil {
+				return fmt.Errorf("err creating folder %s: %w", name, err)
+			}
+			folderID = sql.NullInt32{Int32: folder.ID, Valid: true}
+		}
+
+		_, err = state.Db.CreateFeedFollow(
+			ctx,
+			database.CreateFeedFollowParams{
+				UserID:    in.User.ID,
+				FeedID:    feed.ID,
+				CreatedAt: time.Now().UTC(),
+				UpdatedAt: time.Now().UTC(),
+				FolderID:  folderID,
+				// Keep the name the user gave the feed in their previous reader
+				Title: sql.NullString{String: sub.Name, Valid: sub.Name != "" && sub.Name != feed.Name},
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("err creating feed_follow entry for %s: %w", sub.URL, err)
+		}
+
+		alreadyFollowing[sub.URL] = true
+		followed++
+	}
+
+	// A / would make a subfolder, so folders named with one got a - instead
+	if len(renamed) > 0 {
+		names := make([]string, 0, len(renamed))
+		for folder, name := range renamed {
+			names = append(names, name+" is now "+folder)
+		}
+		sort.Strings(names)
+
+		state.Out.Info("Warning: renamed the folders with a / in their name, %s\n", strings.Join(names, ", "))
+	}
+
+	record := importRecord{Followed: followed, Created: created, Skipped: skipped}
+
+	return state.Out.Item(record, func() {
+		fmt.Printf("Imported %d feeds (%d new), skipped %d already followed\n", followed, created, skipped)
+	})
+}
+
+func handleExport(ctx context.Context, state *state.AppState, in *Input) error {
+	follows, err := state.Db.GetFeedFollowsForUser(ctx, in.User.ID)
+	if err != nil {
+		return fmt.Errorf("err getting feeds for user: %w", err)
+	}
+
+	subs := make([]opml.Subscription, 0, len(follows))
+	for _, follow := range follows {
+		subs = append(subs, opml.Subscription{
+			Name:     follow.Name,
+			URL:      follow.Url,
+			Category: follow.Folder.String,
+		})
+	}
+
+	doc := opml.New("gator subscriptions of "+in.User.Name, in.User.Name, subs)
+
+	// Without a file the document goes to stdout so it can be piped
+	var w io.Writer = os.Stdout
+
+	if in.Arg("file") != "" {
+		f, err := os.Create(in.Arg("file"))
+		if err != nil {
+			return fmt.Errorf("err creating export file: %w", err)
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	err = doc.Write(w)
+	if err != nil {
+		return fmt.Errorf("err writing opml: %w", err)
+	}
+
+	if in.Arg("file") != "" {
+		fmt.Printf("Exported %d feeds to %s\n", len(subs), in.Arg("file"))
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/config"
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+func handlePrune(ctx context.Context, state *state.AppState, in *Input) error {
+	// The flags override the policy of the config
+	policy := state.Cfg.Retention
+	if in.IsSet("max-age") {
+		policy.MaxAge = in.String("max-age")
+	}
+	if in.IsSet("max-posts") {
+		policy.MaxPostsPerFeed = in.Int("max-posts")
+	}
+	if in.IsSet("keep-unread") {
+		policy.KeepUnread = in.Bool("keep-unread")
+	}
+
+	if policy.MaxAge == "" && policy.MaxPostsPerFeed <= 0 {
+		return NewUserFacingError(
+			"no retention policy, nothing to prune",
+			`set "retention": {"max_age": "30d", "max_posts_per_feed": 500, "keep_unread": true} in ~/.gatorconfig.json, or use --max-age / --max-posts`,
+		)
+	}
+
+	// Pruned posts are remembered by key, which must be the real one (agg does it too before pruning)
+	if !in.Bool("dry-run") {
+		err := rekeyLegacyPosts(ctx, state)
+		if err != nil {
+			return err
+		}
+	}
+
+	return prunePosts(ctx, state, policy, in.Bool("dry-run"))
+}
+
+// prunePosts applies a retention policy and reports how many posts were removed per feed
+func prunePosts(ctx context.Context, state *state.AppState, policy config.Retention, dryRun bool) error {
+	arg := database.PrunePostsParams{
+		MaxPosts:   int32(max(policy.MaxPostsPerFeed, 0)),
+		KeepUnread: policy.KeepUnread,
+		DryRun:     dryRun,
+		Now:        time.Now().UTC(),
+	}
+
+	if policy.MaxAge != "" {
+		age, err := parseDuration(policy.MaxAge)
+		if err != nil {
+			return NewUserFacingError("invalid max age '"+policy.MaxAge+"'", "e.g: 720h or 30d")
+		}
+		arg.OlderThan = sql.NullTime{Time: arg.Now.Add(-age), Valid: true}
+	}
+
+	removed, err := state.Db.PrunePosts(ctx, arg)
+	if err != nil {
+		return fmt.Errorf("err pruning posts: %w", err)
+	}
+
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+
+	records := make([]pruneRecord, 0, len(removed))
+	for _, feed := range removed {
+		records = append(records, pruneRecord{
+			FeedID:  feed.ID,
+			Feed:    feed.Name,
+			Removed: feed.Removed,
+			DryRun:  dryRun,
+		})
+	}
+
+	return state.Out.List(records, func() {
+		total := int64(0)
+		for _, feed := range records {
+			fmt.Printf("%s %d posts from %s\n", verb, feed.Removed, feed.Feed)
+			total += feed.Removed
+		}
+
+		fmt.Printf("%s %d posts in total\n", verb, total)
+	})
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/feedgen"
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+func handlePublish(ctx context.Context, state *state.AppState, in *Input) error {
+	// --format is one of the feedgen formats, see its choices
+	feedFormat := feedgen.Format(in.String("format"))
+
+	if in.Int("limit") < 1 {
+		return NewUserFacingError("--limit must be at least 1", "e.g: gator publish paul --limit 50")
+	}
+
+	// An RSS channel must have a link, Atom and JSON Feed only use it when given
+	link := in.String("link")
+	if feedFormat == feedgen.FormatRSS && link == "" {
+		return NewUserFacingError("an rss feed needs --link, the url it will be served from", "e.g: gator publish paul --format rss --link https://example.com/river.xml")
+	}
+
+	user, err := state.Db.FindUserByName(ctx, in.Arg("user"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewUserFacingError("user not found in database", "use gator users to see registered users")
+		}
+		return fmt.Errorf("error finding user by name: %w", err)
+	}
+
+	posts, err := state.Db.GetPostsForUser(
+		ctx,
+		database.GetPostsForUserParams{
+			UserID: user.ID,
+			Limit:  int32(in.Int("limit")),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err getting posts for user: %w", err)
+	}
+
+	err = feedgen.Write(os.Stdout, feedFormat, feedgen.NewRiver(user, posts, link))
+	if err != nil {
+		return fmt.Errorf("err writing feed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+func handleRead(ctx context.Context, state *state.AppState, in *Input) error {
+	postID, err := strconv.Atoi(in.Arg("post-id"))
+	if err != nil {
+		return NewUserFacingError("post id is not a number", "use gator browse to see the ids of the posts")
+	}
+
+	post, err := state.Db.FindPostForUser(
+		ctx,
+		database.FindPostForUserParams{
+			UserID: in.User.ID,
+			ID:     int32(postID),
+		},
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewUserFacingError("no post with this id in the feeds you follow", "use gator browse to see the ids of the posts")
+		}
+		return fmt.Errorf("err finding post: %w", err)
+	}
+
+	err = state.Db.MarkPostRead(
+		ctx,
+		database.MarkPostReadParams{
+			UserID: in.User.ID,
+			PostID: post.ID,
+			ReadAt: time.Now().UTC(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err marking post read: %w", err)
+	}
+
+	state.Out.Info("Marked '%s' as read\n", post.Title)
+
+	return nil
+}
+
+func handleMarkAllRead(ctx context.Context, state *state.AppState, in *Input) error {
+	if in.Arg("feed-url") == "" {
+		marked, err := state.Db.MarkAllPostsRead(
+			ctx,
+			database.MarkAllPostsReadParams{
+				UserID: in.User.ID,
+				ReadAt: time.Now().UTC(),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("err marking all posts read: %w", err)
+		}
+
+		return state.Out.Item(countRecord{Count: marked}, func() {
+			fmt.Printf("Marked %d posts as read\n", marked)
+		})
+	}
+
+	feed, err := state.Db.FindFeedByURL(ctx, in.Arg("feed-url"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewUserFacingError("no feed with specified url exists in your db", "use gator following to see the feeds you are following")
+		}
+		return fmt.Errorf("err query findFeedByUrl: %w", err)
+	}
+
+	marked, err := state.Db.MarkAllFeedPostsRead(
+		ctx,
+		database.MarkAllFeedPostsReadParams{
+			UserID: in.User.ID,
+			FeedID: feed.ID,
+			ReadAt: time.Now().UTC(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err marking feed posts read: %w", err)
+	}
+
+	return state.Out.Item(countRecord{Count: marked}, func() {
+		fmt.Printf("Marked %d posts of %s as read\n", marked, feed.Name)
+	})
+}
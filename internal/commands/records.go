@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+)
+
+// Records are what commands print with --output json, ndjson, csv or tsv.
+// Their json tags are the field names in every format.
+
+type userRecord struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Current   bool      `json:"current"`
+}
+
+type feedRecord struct {
+	ID                  int32      `json:"id"`
+	Name                string     `json:"name"`
+	URL                 string     `json:"url"`
+	CreatedAt           time.Time  `json:"created_at"`
+	LastFetchedAt       *time.Time `json:"last_fetched_at"`
+	LastSuccessAt       *time.Time `json:"last_success_at"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
+	LastError           string     `json:"last_error"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+}
+
+type followRecord struct {
+	FeedID      int32  `json:"feed_id"`
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Folder      string `json:"folder"`
+	UnreadCount int64  `json:"unread_count"`
+}
+
+type postRecord struct {
+	ID          int32     `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Feed        string    `json:"feed"`
+	PublishedAt time.Time `json:"published_at"`
+	IsRead      bool      `json:"is_read"`
+}
+
+type searchRecord struct {
+	ID          int32     `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Feed        string    `json:"feed"`
+	PublishedAt time.Time `json:"published_at"`
+	Rank        float32   `json:"rank"`
+}
+
+// countRecord reports how many things a command changed
+type countRecord struct {
+	Count int64 `json:"count"`
+}
+
+type postDetailRecord struct {
+	ID          int32             `json:"id"`
+	Title       string            `json:"title"`
+	URL         string            `json:"url"`
+	Feed        string            `json:"feed"`
+	Author      string            `json:"author"`
+	Categories  []string          `json:"categories"`
+	PublishedAt time.Time         `json:"published_at"`
+	ImageURL    string            `json:"image_url"`
+	Enclosures  []enclosureRecord `json:"enclosures"`
+	HTML        string            `json:"html"`
+	Text        string            `json:"text"`
+}
+
+type enclosureRecord struct {
+	URL    string `json:"url"`
+	Type   string `json:"type"`
+	Length int64  `json:"length"`
+}
+
+// diffRecord is a line added or removed between two versions of a post
+type diffRecord struct {
+	PostID int32     `json:"post_id"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Change string    `json:"change"`
+	Text   string    `json:"text"`
+}
+
+type filterRecord struct {
+	ID        int32  `json:"id"`
+	Action    string `json:"action"`
+	Field     string `json:"field"`
+	MatchType string `json:"match_type"`
+	Pattern   string `json:"pattern"`
+	Feed      string `json:"feed"` // empty for the rules applied to every feed
+}
+
+type folderRecord struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type pruneRecord struct {
+	FeedID  int32  `json:"feed_id"`
+	Feed    string `json:"feed"`
+	Removed int64  `json:"removed"`
+	DryRun  bool   `json:"dry_run"`
+}
+
+// tokenRecord only holds the token itself right after it's created
+type tokenRecord struct {
+	ID        int32     `json:"id"`
+	Name      string    `json:"name"`
+	Scope     string    `json:"scope"`
+	CreatedAt time.Time `json:"created_at"`
+	Token     string    `json:"token,omitempty"`
+}
+
+type importRecord struct {
+	Followed int `json:"followed"`
+	Created  int `json:"created"`
+	Skipped  int `json:"skipped"`
+}
+
+func toFeedRecord(feed database.Feed) feedRecord {
+	return feedRecord{
+		ID:                  feed.ID,
+		Name:                feed.Name,
+		URL:                 feed.Url,
+		CreatedAt:           feed.CreatedAt,
+		LastFetchedAt:       nullTime(feed.LastFetchedAt),
+		LastSuccessAt:       nullTime(feed.LastSuccessAt),
+		ConsecutiveFailures: feed.ConsecutiveFailures,
+		LastError:           feed.LastError.String,
+		DisabledAt:          nullTime(feed.DisabledAt),
+	}
+}
+
+// nullTime is nil for a NULL time, so it's null in json and empty in csv
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func toFeedRecords(feeds []database.Feed) []feedRecord {
+	records := make([]feedRecord, 0, len(feeds))
+	for _, feed := range feeds {
+		records = append(records, toFeedRecord(feed))
+	}
+	return records
+}
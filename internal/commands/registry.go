@@ -0,0 +1,363 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+type Handler func(ctx context.Context, state *state.AppState, in *Input) error
+
+// Definition declares a command: its params, flags and help. The registry parses and validates
+// the command line with it before calling the handler, and generates the help from it.
+type Definition struct {
+	Name        string
+	Group       string
+	Description string
+	Args        []Arg
+	Flags       []Flag
+	Examples    []string
+	// Subcommands are picked by the first param, e.g. folder create, the command itself has no handler
+	Subcommands []*Definition
+	// RequiresLogin commands get the current user in Input.User
+	RequiresLogin bool
+	// TextOnly commands print their own format, --output can't be used with them
+	TextOnly bool
+	Handler  Handler
+
+	parent *Definition
+}
+
+// Arg is a positional param
+type Arg struct {
+	Name     string
+	Usage    string
+	Optional bool
+	// Variadic takes all the remaining params, it must be the last arg
+	Variadic bool
+	// Choices are the only values accepted, and with Complete what gator completion offers
+	Complete Completion
+	Choices  []string
+}
+
+type FlagKind int
+
+const (
+	FlagString FlagKind = iota
+	FlagBool
+	FlagInt
+	FlagDuration
+)
+
+type Flag struct {
+	Name  string
+	Kind  FlagKind
+	Usage string
+	// Value names what the flag takes in the usage, e.g. <url>
+	Value string
+	// Default is parsed according to Kind, empty means the zero value
+	Default string
+	// Choices are the only values accepted, and with Complete what gator completion offers
+	Complete Completion
+	Choices  []string
+}
+
+// Completion is a kind of value the shell completion looks up when the user presses tab
+type Completion string
+
+const (
+	CompleteFiles Completion = "files"
+	// CompleteCommands only completes the first param, with the command names
+	CompleteCommands      Completion = "commands"
+	CompleteUsers         Completion = "users"
+	CompleteFeeds         Completion = "feeds"
+	CompleteFollowedFeeds Completion = "followed-feeds"
+	CompleteFolders       Completion = "folders"
+)
+
+// Help groups, in the order gator help shows them
+const (
+	GroupUsers   = "User Management"
+	GroupFeeds   = "Feed Management"
+	GroupContent = "Content"
+	GroupSystem  = "System"
+)
+
+var groups = []string{GroupUsers, GroupFeeds, GroupContent, GroupSystem}
+
+var mapCommands = make(map[string]*Definition)
+
+// definitions keeps the registration order for the help
+var definitions []*Definition
+
+func registerCommand(def *Definition) {
+	if _, exists := mapCommands[def.Name]; exists {
+		log.Printf("Warning: Command '%s' is being registered more than once.", def.Name)
+	}
+
+	for _, sub := range def.Subcommands {
+		sub.parent = def
+		sub.Group = def.Group
+		sub.RequiresLogin = def.RequiresLogin
+		sub.TextOnly = sub.TextOnly || def.TextOnly
+	}
+
+	mapCommands[def.Name] = def
+	definitions = append(definitions, def)
+}
+
+// Input is a parsed command line
+type Input struct {
+	Args []string
+	// User is the current user, for the commands requiring login
+	User database.User
+
+	named map[string]string
+	flags *flag.FlagSet
+}
+
+// Arg returns a positional param by name, empty when an optional arg was not given
+func (in *Input) Arg(name string) string {
+	return in.named[name]
+}
+
+func (in *Input) String(name string) string {
+	return in.value(name).(string)
+}
+
+func (in *Input) Bool(name string) bool {
+	return in.value(name).(bool)
+}
+
+func (in *Input) Int(name string) int {
+	return in.value(name).(int)
+}
+
+func (in *Input) Duration(name string) time.Duration {
+	return in.value(name).(time.Duration)
+}
+
+// IsSet tells if a flag was given on the command line, as opposed to having its default value
+func (in *Input) IsSet(name string) bool {
+	set := false
+	in.flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func (in *Input) value(name string) any {
+	f := in.flags.Lookup(name)
+	if f == nil {
+		panic("flag --" + name + " is not declared")
+	}
+	return f.Value.(flag.Getter).Get()
+}
+
+// Path is the full name of the command, e.g. folder move
+func (d *Definition) Path() string {
+	if d.parent == nil {
+		return d.Name
+	}
+	return d.parent.Path() + " " + d.Name
+}
+
+// Usage is the synopsis of the command, e.g. browse [flags] [limit]
+func (d *Definition) Usage() string {
+	parts := []string{d.Path()}
+
+	if len(d.Subcommands) > 0 {
+		names := make([]string, 0, len(d.Subcommands))
+		for _, sub := range d.Subcommands {
+			names = append(names, sub.Name)
+		}
+		parts = append(parts, strings.Join(names, "|"))
+	}
+
+	if len(d.Flags) > 0 {
+		parts = append(parts, "[flags]")
+	}
+
+	for _, arg := range d.Args {
+		name := "<" + arg.Name + ">"
+		if arg.Variadic {
+			name = "<" + arg.Name + "...>"
+		}
+		if arg.Optional {
+			name = "[" + strings.Trim(name, "<>") + "]"
+		}
+		parts = append(parts, name)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (f Flag) usage() string {
+	if f.Kind == FlagBool {
+		return "--" + f.Name
+	}
+
+	value := f.Value
+	if value == "" {
+		value = "value"
+	}
+	return "--" + f.Name + " <" + value + ">"
+}
+
+func (d *Definition) subcommand(name string) *Definition {
+	for _, sub := range d.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// usageError is the error for a command line not matching the definition
+func (d *Definition) usageError(problem string) error {
+	suggestion := "see gator help " + d.Path()
+	if len(d.Examples) > 0 {
+		suggestion = "e.g: " + d.Examples[0] + ", or see gator help " + d.Path()
+	}
+
+	return NewUserFacingError(fmt.Sprintf("%s, usage: gator %s", problem, d.Usage()), suggestion)
+}
+
+func (d *Definition) flagSet() *flag.FlagSet {
+	fs := newFlagSet(d.Path())
+
+	for _, f := range d.Flags {
+		var err error
+
+		switch f.Kind {
+		case FlagString:
+			fs.String(f.Name, f.Default, f.Usage)
+		case FlagBool:
+			fs.Bool(f.Name, f.Default == "true", f.Usage)
+		case FlagInt:
+			value := 0
+			if f.Default != "" {
+				value, err = strconv.Atoi(f.Default)
+			}
+			fs.Int(f.Name, value, f.Usage)
+		case FlagDuration:
+			var value time.Duration
+			if f.Default != "" {
+				value, err = time.ParseDuration(f.Default)
+			}
+			fs.Duration(f.Name, value, f.Usage)
+		}
+
+		if err != nil {
+			panic(fmt.Sprintf("invalid default of flag --%s of %s: %v", f.Name, d.Path(), err))
+		}
+	}
+
+	return fs
+}
+
+// parse checks params against the definition and names them
+func (d *Definition) parse(params []string) (*Input, error) {
+	fs := d.flagSet()
+
+	args, err := parseFlags(fs, params)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		// The flag package speaks of -name, flags are documented as --name
+		return nil, d.usageError(strings.Replace(err.Error(), " -", " --", 1))
+	}
+
+	required, variadic := 0, false
+	for _, arg := range d.Args {
+		if !arg.Optional {
+			required++
+		}
+		variadic = variadic || arg.Variadic
+	}
+
+	if len(args) < required {
+		missing := d.Args[0]
+		// The missing arg is the first required one not given, optional args are only filled with extra params
+		count := 0
+		for _, arg := range d.Args {
+			if arg.Optional {
+				continue
+			}
+			if count == len(args) {
+				missing = arg
+				break
+			}
+			count++
+		}
+		return nil, d.usageError("missing <" + missing.Name + ">")
+	}
+
+	if !variadic && len(args) > len(d.Args) {
+		return nil, d.usageError(fmt.Sprintf("too many params, %s takes at most %d", d.Path(), len(d.Args)))
+	}
+
+	// Defaults are not checked, --export has none for instance
+	for _, f := range d.Flags {
+		value := fs.Lookup(f.Name).Value.String()
+		if len(f.Choices) > 0 && value != f.Default && !slices.Contains(f.Choices, value) {
+			return nil, d.usageError(fmt.Sprintf("--%s must be %s, not '%s'", f.Name, choiceList(f.Choices), value))
+		}
+	}
+
+	// Optional args are filled from the left with the params left once the required ones are counted
+	named := make(map[string]string, len(d.Args))
+	extra := len(args) - required
+	i := 0
+
+	for _, arg := range d.Args {
+		if i >= len(args) {
+			break
+		}
+
+		if arg.Optional && extra == 0 {
+			continue
+		}
+
+		if arg.Variadic {
+			named[arg.Name] = strings.Join(args[i:], " ")
+			i = len(args)
+			break
+		}
+
+		if arg.Optional {
+			extra--
+		}
+		named[arg.Name] = args[i]
+		i++
+	}
+
+	for _, arg := range d.Args {
+		value, given := named[arg.Name]
+		if given && len(arg.Choices) > 0 && !slices.Contains(arg.Choices, value) {
+			return nil, d.usageError(fmt.Sprintf("<%s> must be %s, not '%s'", arg.Name, choiceList(arg.Choices), value))
+		}
+	}
+
+	return &Input{Args: args, named: named, flags: fs}, nil
+}
+
+// choiceList reads "a, b or c"
+func choiceList(choices []string) string {
+	if len(choices) == 1 {
+		return choices[0]
+	}
+	return strings.Join(choices[:len(choices)-1], ", ") + " or " + choices[len(choices)-1]
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+func handleRename(ctx context.Context, state *state.AppState, in *Input) error {
+	feed, err := state.Db.FindFeedByURL(ctx, in.Arg("url"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewUserFacingError("no feed with specified url exists in your db", "use gator following to see the feeds you are following")
+		}
+		return fmt.Errorf("err finding feed: %w", err)
+	}
+
+	// The title is only seen by this user, without one the feed name is shown again
+	title := strings.TrimSpace(in.Arg("title"))
+
+	updated, err := state.Db.SetFeedFollowTitle(
+		ctx,
+		database.SetFeedFollowTitleParams{
+			UserID:    in.User.ID,
+			FeedID:    feed.ID,
+			Title:     sql.NullString{String: title, Valid: title != ""},
+			UpdatedAt: time.Now().UTC(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err renaming feed: %w", err)
+	}
+
+	if updated == 0 {
+		return NewUserFacingError("you don't follow this feed", "use gator follow <url> to follow it first")
+	}
+
+	if title == "" {
+		state.Out.Info("Feed is named %s again\n", feed.Name)
+		return nil
+	}
+
+	state.Out.Info("Feed %s renamed to %s for you\n", feed.Name, title)
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+func handleSearch(ctx context.Context, state *state.AppState, in *Input) error {
+	feedURL, since, until := in.String("feed"), in.String("since"), in.String("until")
+
+	if in.Int("limit") < 1 {
+		return NewUserFacingError("--limit must be at least 1", "e.g: gator search --limit 50 golang")
+	}
+
+	arg := database.SearchPostsForUserParams{
+		Query:      in.Arg("query"),
+		UserID:     in.User.ID,
+		FeedUrl:    sql.NullString{String: feedURL, Valid: feedURL != ""},
+		MaxResults: int32(in.Int("limit")),
+	}
+
+	var err error
+
+	if since != "" {
+		arg.Since.Time, err = parseSince(since)
+		if err != nil {
+			return NewUserFacingError("invalid --since value", "e.g: 2024-01-31, 72h or 30d")
+		}
+		arg.Since.Valid = true
+	}
+
+	if until != "" {
+		arg.Until.Time, err = parseUntil(until)
+		if err != nil {
+			return NewUserFacingError("invalid --until value", "e.g: 2024-01-31, 72h or 30d")
+		}
+		arg.Until.Valid = true
+	}
+
+	results, err := state.Db.SearchPostsForUser(ctx, arg)
+	if err != nil {
+		return fmt.Errorf("err searching posts: %w", err)
+	}
+
+	records := make([]searchRecord, 0, len(results))
+	for _, result := range results {
+		records = append(records, searchRecord{
+			ID:          result.ID,
+			Title:       result.Title,
+			URL:         result.Url,
+			Feed:        result.FeedName,
+			PublishedAt: result.PublishedAt,
+			Rank:        result.Rank,
+		})
+	}
+
+	return state.Out.List(records, func() {
+		if len(results) == 0 {
+			fmt.Println("No posts found")
+			return
+		}
+
+		for _, result := range results {
+			fmt.Println("------------")
+			fmt.Printf("ID: %d\n", result.ID)
+			fmt.Printf("Title: %s\n", result.Title)
+			fmt.Printf("Feed: %s\n", result.FeedName)
+			fmt.Printf("Published: %s\n", result.PublishedAt.Format(time.DateOnly))
+			fmt.Printf("Link: %s\n", result.Url)
+			fmt.Println("------------")
+		}
+	})
+}
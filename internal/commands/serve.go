@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/api"
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+func handleServe(ctx context.Context, state *state.AppState, in *Input) error {
+	addr := in.Arg("addr")
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           api.NewServer(state.Db).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Stop accepting connections when asked to stop, requests in flight get some time to finish
+	shutdownDone := make(chan struct{})
+
+	go func() {
+		defer close(shutdownDone)
+
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			fmt.Println(fmt.Errorf("err shutting down server: %w", err))
+		}
+	}()
+
+	fmt.Printf("Serving the gator API on %s\n", addr)
+
+	err := server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		<-shutdownDone
+		return nil
+	}
+
+	return fmt.Errorf("err serving api: %w", err)
+}
+
+func handleTokenCreate(ctx context.Context, state *state.AppState, in *Input) error {
+	token, hash, err := api.GenerateToken()
+	if err != nil {
+		return fmt.Errorf("err generating token: %w", err)
+	}
+
+	scope := api.ScopeAPI
+	if in.Bool("publish") {
+		scope = api.ScopePublish
+	}
+
+	created, err := state.Db.CreateAPIToken(
+		ctx,
+		database.CreateAPITokenParams{
+			CreatedAt: time.Now().UTC(),
+			Name:      in.Arg("name"),
+			TokenHash: hash,
+			UserID:    in.User.ID,
+			Scope:     scope,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err creating api token: %w", err)
+	}
+
+	record := tokenRecord{ID: created.ID, Name: created.Name, Scope: created.Scope, CreatedAt: created.CreatedAt, Token: token}
+
+	return state.Out.Item(record, func() {
+		if scope == api.ScopePublish {
+			fmt.Printf("Publish token for %s (it won't be shown again), your feed is served by gator serve at:\n/river/%s\n", in.User.Name, token)
+			return
+		}
+		fmt.Printf("Token for %s (it won't be shown again):\n%s\n", in.User.Name, token)
+	})
+}
+
+func handleTokenList(ctx context.Context, state *state.AppState, in *Input) error {
+	tokens, err := state.Db.GetAPITokensForUser(ctx, in.User.ID)
+	if err != nil {
+		return fmt.Errorf("err getting api tokens: %w", err)
+	}
+
+	records := make([]tokenRecord, 0, len(tokens))
+	for _, token := range tokens {
+		records = append(records, tokenRecord{ID: token.ID, Name: token.Name, Scope: token.Scope, CreatedAt: token.CreatedAt})
+	}
+
+	return state.Out.List(records, func() {
+		for _, token := range records {
+			fmt.Printf("%d  %s  %s  (created %s)\n", token.ID, token.Name, token.Scope, token.CreatedAt.Format(time.DateOnly))
+		}
+	})
+}
+
+func handleTokenRevoke(ctx context.Context, state *state.AppState, in *Input) error {
+	id, err := strconv.Atoi(in.Arg("id"))
+	if err != nil {
+		return NewUserFacingError("token id is not a number", "use gator token list to see your tokens")
+	}
+
+	deleted, err := state.Db.DeleteAPIToken(
+		ctx,
+		database.DeleteAPITokenParams{
+			ID:     int32(id),
+			UserID: in.User.ID,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err deleting api token: %w", err)
+	}
+
+	if deleted == 0 {
+		return NewUserFacingError("you have no token with this id", "use gator token list to see your tokens")
+	}
+
+	state.Out.Info("Token revoked\n")
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/render"
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+// defaultShowWidth is used when the terminal width is unknown ($COLUMNS not exported)
+const defaultShowWidth = 80
+
+func handleShow(ctx context.Context, state *state.AppState, in *Input) error {
+	postID, err := strconv.Atoi(in.Arg("post-id"))
+	if err != nil {
+		return NewUserFacingError("post id is not a number", "use gator browse to see the ids of the posts")
+	}
+
+	post, err := state.Db.FindPostForUser(
+		ctx,
+		database.FindPostForUserParams{
+			UserID: in.User.ID,
+			ID:     int32(postID),
+		},
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewUserFacingError("no post with this id in the feeds you follow", "use gator browse to see the ids of the posts")
+		}
+		return fmt.Errorf("err finding post: %w", err)
+	}
+
+	enclosures, err := state.Db.GetPostEnclosures(ctx, post.ID)
+	if err != nil {
+		return fmt.Errorf("err getting post enclosures: %w", err)
+	}
+
+	width := defaultShowWidth
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		width = columns
+	}
+
+	// Many feeds only have a summary in the description and put the full article in the content
+	body := post.Content
+	if strings.TrimSpace(body) == "" {
+		body = post.Description
+	}
+
+	record := postDetailRecord{
+		ID:          post.ID,
+		Title:       post.Title,
+		URL:         post.Url,
+		Feed:        post.FeedName,
+		Author:      post.Author,
+		Categories:  post.Categories,
+		PublishedAt: post.PublishedAt,
+		ImageURL:    post.ImageUrl,
+		Enclosures:  make([]enclosureRecord, 0, len(enclosures)),
+		HTML:        body,
+		Text:        render.HTMLToText(body, width),
+	}
+	for _, enclosure := range enclosures {
+		record.Enclosures = append(record.Enclosures, enclosureRecord{
+			URL:    enclosure.Url,
+			Type:   enclosure.Type,
+			Length: enclosure.Length,
+		})
+	}
+
+	err = state.Out.Item(record, func() {
+		fmt.Println(post.Title)
+		fmt.Printf("%s · %s\n", post.FeedName, post.PublishedAt.Format(time.DateTime))
+		if post.Author != "" {
+			fmt.Printf("By %s\n", post.Author)
+		}
+		if len(post.Categories) > 0 {
+			fmt.Printf("Tags: %s\n", strings.Join(post.Categories, ", "))
+		}
+		fmt.Println(post.Url)
+		if post.ImageUrl != "" {
+			fmt.Printf("Image: %s\n", post.ImageUrl)
+		}
+		for _, enclosure := range enclosures {
+			fmt.Printf("Attachment: %s (%s)\n", enclosure.Url, describeEnclosure(enclosure))
+		}
+		fmt.Println()
+
+		fmt.Println(record.Text)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = state.Db.MarkPostRead(
+		ctx,
+		database.MarkPostReadParams{
+			UserID: in.User.ID,
+			PostID: post.ID,
+			ReadAt: time.Now().UTC(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err marking post read: %w", err)
+	}
+
+	return nil
+}
+
+func describeEnclosure(enclosure database.PostEnclosure) string {
+	kind := enclosure.Type
+	if kind == "" {
+		kind = "unknown type"
+	}
+
+	if enclosure.Length <= 0 {
+		return kind
+	}
+
+	return fmt.Sprintf("%s, %.1f MB", kind, float64(enclosure.Length)/(1<<20))
+}
@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/render"
+	"github.com/Ciobi0212/gator.git/internal/state"
+)
+
+func handleStar(ctx context.Context, state *state.AppState, in *Input) error {
+	postID, err := strconv.Atoi(in.Arg("post-id"))
+	if err != nil {
+		return NewUserFacingError("post id is not a number", "use gator browse to see the ids of the posts")
+	}
+
+	post, err := state.Db.FindPostForUser(
+		ctx,
+		database.FindPostForUserParams{
+			UserID: in.User.ID,
+			ID:     int32(postID),
+		},
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewUserFacingError("no post with this id in the feeds you follow", "use gator browse to see the ids of the posts")
+		}
+		return fmt.Errorf("err finding post: %w", err)
+	}
+
+	err = state.Db.StarPost(
+		ctx,
+		database.StarPostParams{
+			UserID:    in.User.ID,
+			PostID:    post.ID,
+			StarredAt: time.Now().UTC(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err starring post: %w", err)
+	}
+
+	state.Out.Info("Starred '%s'\n", post.Title)
+
+	return nil
+}
+
+func handleUnstar(ctx context.Context, state *state.AppState, in *Input) error {
+	postID, err := strconv.Atoi(in.Arg("post-id"))
+	if err != nil {
+		return NewUserFacingError("post id is not a number", "use gator starred to see the ids of your starred posts")
+	}
+
+	deleted, err := state.Db.UnstarPost(
+		ctx,
+		database.UnstarPostParams{
+			UserID: in.User.ID,
+			PostID: int32(postID),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("err unstarring post: %w", err)
+	}
+
+	if deleted == 0 {
+		return NewUserFacingError("this post is not starred", "use gator starred to see the ids of your starred posts")
+	}
+
+	state.Out.Info("Post unstarred\n")
+
+	return nil
+}
+
+type starredPost struct {
+	ID          int32     `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Feed        string    `json:"feed"`
+	Author      string    `json:"author,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	StarredAt   time.Time `json:"starred_at"`
+	Summary     string    `json:"summary"`
+}
+
+func handleStarred(ctx context.Context, state *state.AppState, in *Input) error {
+	export, file := in.String("export"), in.Arg("file")
+
+	if file != "" && export == "" {
+		return NewUserFacingError("a file is only given with --export", "e.g: gator starred --export markdown reading-list.md")
+	}
+
+	if export != "" && !state.Out.IsText() {
+		return NewUserFacingError("--export writes its own format, it can't be used with --output", "e.g: gator starred --output json, gator starred --export json")
+	}
+
+	posts, err := state.Db.GetStarredPostsForUser(ctx, in.User.ID)
+	if err != nil {
+		return fmt.Errorf("err getting starred posts: %w", err)
+	}
+
+	starred := make([]starredPost, 0, len(posts))
+	for _, post := range posts {
+		starred = append(starred, starredPost{
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			Feed:        post.FeedName,
+			Author:      post.Author,
+			PublishedAt: post.PublishedAt,
+			StarredAt:   post.StarredAt,
+			Summary:     summary(post.Description),
+		})
+	}
+
+	if export == "" {
+		return state.Out.List(starred, func() {
+			if len(starred) == 0 {
+				fmt.Println("No starred posts, star one with gator star <post-id>")
+				return
+			}
+
+			for _, post := range starred {
+				fmt.Printf("%d  %s  (%s, starred %s)\n", post.ID, post.Title, post.Feed, post.StarredAt.Format(time.DateOnly))
+				fmt.Printf("    %s\n", post.URL)
+			}
+		})
+	}
+
+	// Without a file the export goes to stdout so it can be piped
+	var w io.Writer = os.Stdout
+
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return fmt.Errorf("err creating export file: %w", err)
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	if export == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(starred)
+	} else {
+		err = writeStarredMarkdown(w, in.User.Name, starred)
+	}
+	if err != nil {
+		return fmt.Errorf("err writing starred posts: %w", err)
+	}
+
+	if file != "" {
+		fmt.Printf("Exported %d starred posts to %s\n", len(starred), file)
+	}
+
+	return nil
+}
+
+func writeStarredMarkdown(w io.Writer, username string, posts []starredPost) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# Starred posts of %s\n", username)
+
+	for _, post := range posts {
+		title := strings.NewReplacer("[", `\[`, "]", `\]`).Replace(post.Title)
+
+		fmt.Fprintf(&sb, "\n## [%s](%s)\n\n", title, post.URL)
+
+		meta := post.Feed
+		if post.Author != "" {
+			meta += " · " + post.Author
+		}
+		if !post.PublishedAt.IsZero() {
+			meta += " · " + post.PublishedAt.Format(time.DateOnly)
+		}
+		fmt.Fprintf(&sb, "*%s*\n", meta)
+
+		if post.Summary != "" {
+			fmt.Fprintf(&sb, "\n> %s\n", strings.ReplaceAll(post.Summary, "\n", "\n> "))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// summary is the first paragraph of a post description, as plain text
+func summary(description string) string {
+	text := render.HTMLToText(description, 0)
+	paragraph, _, _ := strings.Cut(text, "\n\n")
+	return strings.TrimSpace(paragraph)
+}
@@ -0,0 +1,12 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/Ciobi0212/gator.git/internal/state"
+	"github.com/Ciobi0212/gator.git/internal/tui"
+)
+
+func handleTui(ctx context.Context, state *state.AppState, in *Input) error {
+	return tui.Run(ctx, state.Db, in.User)
+}
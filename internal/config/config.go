@@ -9,8 +9,19 @@ import (
 const configName string = ".gatorconfig.json"
 
 type Config struct {
-	Db_url           string `json:"db_url"`
-	Current_username string `json:"current_username"`
+	Db_url           string    `json:"db_url"`
+	Current_username string    `json:"current_username"`
+	Retention        Retention `json:"retention"`
+}
+
+// Retention is the policy applied by gator prune. Starred posts are always kept.
+type Retention struct {
+	// MaxAge is a duration like 720h or 30d, empty keeps posts of any age
+	MaxAge string `json:"max_age,omitempty"`
+	// MaxPostsPerFeed keeps only the most recent posts of each feed, 0 for no limit
+	MaxPostsPerFeed int `json:"max_posts_per_feed,omitempty"`
+	// KeepUnread keeps the posts a follower of their feed hasn't read yet
+	KeepUnread bool `json:"keep_unread,omitempty"`
 }
 
 func getConfigPath() (string, error) {
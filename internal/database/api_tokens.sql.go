@@ -0,0 +1,124 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: api_tokens.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createAPIToken = `-- name: CreateAPIToken :one
+INSERT INTO api_tokens (created_at, name, token_hash, user_id, scope)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, name, token_hash, user_id, scope
+`
+
+type CreateAPITokenParams struct {
+	CreatedAt time.Time
+	Name      string
+	TokenHash string
+	UserID    uuid.UUID
+	Scope     string
+}
+
+func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, createAPIToken,
+		arg.CreatedAt,
+		arg.Name,
+		arg.TokenHash,
+		arg.UserID,
+		arg.Scope,
+	)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.Name,
+		&i.TokenHash,
+		&i.UserID,
+		&i.Scope,
+	)
+	return i, err
+}
+
+const deleteAPIToken = `-- name: DeleteAPIToken :execrows
+DELETE FROM api_tokens
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteAPITokenParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteAPIToken(ctx context.Context, arg DeleteAPITokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAPIToken, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const findUserByAPIToken = `-- name: FindUserByAPIToken :one
+SELECT users.id, users.created_at, users.updated_at, users.name FROM users
+JOIN api_tokens ON api_tokens.user_id = users.id
+WHERE api_tokens.token_hash = $1 AND api_tokens.scope = $2
+`
+
+type FindUserByAPITokenParams struct {
+	TokenHash string
+	Scope     string
+}
+
+func (q *Queries) FindUserByAPIToken(ctx context.Context, arg FindUserByAPITokenParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, findUserByAPIToken, arg.TokenHash, arg.Scope)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+	)
+	return i, err
+}
+
+const getAPITokensForUser = `-- name: GetAPITokensForUser :many
+SELECT id, created_at, name, token_hash, user_id, scope FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetAPITokensForUser(ctx context.Context, userID uuid.UUID) ([]ApiToken, error) {
+	rows, err := q.db.QueryContext(ctx, getAPITokensForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiToken
+	for rows.Next() {
+		var i ApiToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.Name,
+			&i.TokenHash,
+			&i.UserID,
+			&i.Scope,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,9 +7,50 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
+const claimNextFeedToFetch = `-- name: ClaimNextFeedToFetch :one
+UPDATE feed
+SET next_fetch_at = $1::timestamp
+WHERE id = (
+    SELECT id from feed
+    WHERE disabled_at IS NULL
+    AND (next_fetch_at IS NULL OR next_fetch_at <= $2::timestamp)
+    ORDER BY next_fetch_at ASC NULLS FIRST, last_fetched_at ASC NULLS FIRST
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, name, url, created_at, updated_at, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at
+`
+
+type ClaimNextFeedToFetchParams struct {
+	LeaseUntil time.Time
+	Now        time.Time
+}
+
+func (q *Queries) ClaimNextFeedToFetch(ctx context.Context, arg ClaimNextFeedToFetchParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, claimNextFeedToFetch, arg.LeaseUntil, arg.Now)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Url,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feed(name, url,created_at,updated_at)
 VALUES (
@@ -18,7 +59,7 @@ VALUES (
     $3,
     $4
 )
-RETURNING id, name, url, created_at, updated_at, last_fetched_at
+RETURNING id, name, url, created_at, updated_at, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at
 `
 
 type CreateFeedParams struct {
@@ -43,6 +84,13 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
 	)
 	return i, err
 }
@@ -56,8 +104,30 @@ func (q *Queries) DeleteAllFeeds(ctx context.Context) error {
 	return err
 }
 
+const disableFeed = `-- name: DisableFeed :exec
+UPDATE feed
+SET disabled_at = NOW(), updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) DisableFeed(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, disableFeed, id)
+	return err
+}
+
+const enableFeed = `-- name: EnableFeed :exec
+UPDATE feed
+SET disabled_at = NULL, consecutive_failures = 0, next_fetch_at = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) EnableFeed(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, enableFeed, id)
+	return err
+}
+
 const findFeedByURL = `-- name: FindFeedByURL :one
-select id, name, url, created_at, updated_at, last_fetched_at from feed 
+select id, name, url, created_at, updated_at, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at from feed 
 WHERE url = $1
 `
 
@@ -71,12 +141,19 @@ func (q *Queries) FindFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
 	)
 	return i, err
 }
 
 const getAllFeeds = `-- name: GetAllFeeds :many
-SELECT id, name, url, created_at, updated_at, last_fetched_at FROM feed
+SELECT id, name, url, created_at, updated_at, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at FROM feed
 `
 
 func (q *Queries) GetAllFeeds(ctx context.Context) ([]Feed, error) {
@@ -95,6 +172,13 @@ func (q *Queries) GetAllFeeds(ctx context.Context) ([]Feed, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
 		); err != nil {
 			return nil, err
 		}
@@ -109,14 +193,14 @@ func (q *Queries) GetAllFeeds(ctx context.Context) ([]Feed, error) {
 	return items, nil
 }
 
-const getNextFeedsToFetch = `-- name: GetNextFeedsToFetch :many
-SELECT id, name, url, created_at, updated_at, last_fetched_at from feed 
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT $1
+const getBrokenFeeds = `-- name: GetBrokenFeeds :many
+SELECT id, name, url, created_at, updated_at, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled_at FROM feed
+WHERE consecutive_failures > 0 OR disabled_at IS NOT NULL
+ORDER BY consecutive_failures DESC
 `
 
-func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed, error) {
-	rows, err := q.db.QueryContext(ctx, getNextFeedsToFetch, limit)
+func (q *Queries) GetBrokenFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getBrokenFeeds)
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +215,13 @@ func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
 		); err != nil {
 			return nil, err
 		}
@@ -155,3 +246,66 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id int32) error {
 	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
 	return err
 }
+
+const recordFeedFailure = `-- name: RecordFeedFailure :one
+UPDATE feed
+SET last_error = $2, consecutive_failures = consecutive_failures + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING consecutive_failures
+`
+
+type RecordFeedFailureParams struct {
+	ID        int32
+	LastError sql.NullString
+}
+
+func (q *Queries) RecordFeedFailure(ctx context.Context, arg RecordFeedFailureParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, recordFeedFailure, arg.ID, arg.LastError)
+	var consecutive_failures int32
+	err := row.Scan(&consecutive_failures)
+	return consecutive_failures, err
+}
+
+const recordFeedSuccess = `-- name: RecordFeedSuccess :exec
+UPDATE feed
+SET last_error = NULL, consecutive_failures = 0, last_success_at = NOW(), updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RecordFeedSuccess(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, recordFeedSuccess, id)
+	return err
+}
+
+const setFeedNextFetchAt = `-- name: SetFeedNextFetchAt :exec
+UPDATE feed
+SET next_fetch_at = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedNextFetchAtParams struct {
+	ID          int32
+	NextFetchAt sql.NullTime
+}
+
+func (q *Queries) SetFeedNextFetchAt(ctx context.Context, arg SetFeedNextFetchAtParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedNextFetchAt, arg.ID, arg.NextFetchAt)
+	return err
+}
+
+const setFeedValidators = `-- name: SetFeedValidators :exec
+UPDATE feed
+SET etag = $2, last_modified = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedValidatorsParams struct {
+	ID           int32
+	Etag         sql.NullString
+	LastModified sql.NullString
+}
+
+func (q *Queries) SetFeedValidators(ctx context.Context, arg SetFeedValidatorsParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedValidators, arg.ID, arg.Etag, arg.LastModified)
+	return err
+}
@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,12 +15,12 @@ import (
 
 const createFeedFollow = `-- name: CreateFeedFollow :one
 WITH inserted_feed_follows AS (
-    INSERT INTO feed_follows(created_at, updated_at, user_id, feed_id)
-    VALUES ($1, $2, $3, $4)
-    RETURNING id, created_at, updated_at, user_id, feed_id
+    INSERT INTO feed_follows(created_at, updated_at, user_id, feed_id, folder_id, title)
+    VALUES ($1, $2, $3, $4, $5, $6)
+    RETURNING id, created_at, updated_at, user_id, feed_id, folder_id, title
 )
 
-SELECT inserted_feed_follows.id, inserted_feed_follows.created_at, inserted_feed_follows.updated_at, inserted_feed_follows.user_id, inserted_feed_follows.feed_id, users.name, feed.name 
+SELECT inserted_feed_follows.id, inserted_feed_follows.created_at, inserted_feed_follows.updated_at, inserted_feed_follows.user_id, inserted_feed_follows.feed_id, inserted_feed_follows.folder_id, inserted_feed_follows.title, users.name, COALESCE(inserted_feed_follows.title, feed.name)::varchar AS feed_name
 FROM inserted_feed_follows 
 JOIN users on users.id = inserted_feed_follows.user_id
 JOIN feed on feed.id = inserted_feed_follows.feed_id
@@ -30,6 +31,8 @@ type CreateFeedFollowParams struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    int32
+	FolderID  sql.NullInt32
+	Title     sql.NullString
 }
 
 type CreateFeedFollowRow struct {
@@ -38,8 +41,10 @@ type CreateFeedFollowRow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    int32
+	FolderID  sql.NullInt32
+	Title     sql.NullString
 	Name      string
-	Name_2    string
+	FeedName  string
 }
 
 func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error) {
@@ -48,6 +53,8 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		arg.UpdatedAt,
 		arg.UserID,
 		arg.FeedID,
+		arg.FolderID,
+		arg.Title,
 	)
 	var i CreateFeedFollowRow
 	err := row.Scan(
@@ -56,8 +63,10 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.FolderID,
+		&i.Title,
 		&i.Name,
-		&i.Name_2,
+		&i.FeedName,
 	)
 	return i, err
 }
@@ -89,17 +98,32 @@ func (q *Queries) DeleteFeedFollowsEntry(ctx context.Context, arg DeleteFeedFoll
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 
 WITH feed_follows_entries AS (
-    SELECT id, created_at, updated_at, user_id, feed_id from feed_follows
-    WHERE user_id = $1
+    SELECT id, created_at, updated_at, user_id, feed_id, folder_id, title from feed_follows
+    WHERE feed_follows.user_id = $1
 )
 
-SELECT feed.name, feed.url FROM feed_follows_entries
+SELECT feed.id, COALESCE(feed_follows_entries.title, feed.name)::varchar AS name, feed.url, folders.name AS folder,
+    (
+        SELECT COUNT(*) FROM posts
+        WHERE posts.feed_id = feed.id
+        AND NOT EXISTS (
+            SELECT 1 FROM post_reads
+            WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows_entries.user_id
+        )
+        AND post_passes_filters(feed_follows_entries.user_id, posts.feed_id, posts.title, posts.description, posts.content, posts.author, posts.categories)
+    ) AS unread_count
+FROM feed_follows_entries
 JOIN feed ON feed.id = feed_follows_entries.feed_id
+LEFT JOIN folders ON folders.id = feed_follows_entries.folder_id
+ORDER BY folders.name NULLS FIRST, COALESCE(feed_follows_entries.title, feed.name)
 `
 
 type GetFeedFollowsForUserRow struct {
-	Name string
-	Url  string
+	ID          int32
+	Name        string
+	Url         string
+	Folder      sql.NullString
+	UnreadCount int64
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -111,7 +135,13 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	var items []GetFeedFollowsForUserRow
 	for rows.Next() {
 		var i GetFeedFollowsForUserRow
-		if err := rows.Scan(&i.Name, &i.Url); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.Folder,
+			&i.UnreadCount,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -124,3 +154,29 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	}
 	return items, nil
 }
+
+const setFeedFollowTitle = `-- name: SetFeedFollowTitle :execrows
+UPDATE feed_follows
+SET title = $3, updated_at = $4
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type SetFeedFollowTitleParams struct {
+	UserID    uuid.UUID
+	FeedID    int32
+	Title     sql.NullString
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetFeedFollowTitle(ctx context.Context, arg SetFeedFollowTitleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFollowTitle,
+		arg.UserID,
+		arg.FeedID,
+		arg.Title,
+		arg.UpdatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
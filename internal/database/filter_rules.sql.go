@@ -0,0 +1,135 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: filter_rules.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const checkRegex = `-- name: CheckRegex :exec
+SELECT '' ~* $1::varchar
+`
+
+// Fails when pattern is not a valid regex for postgres, before it is stored and breaks the post queries
+func (q *Queries) CheckRegex(ctx context.Context, pattern string) error {
+	_, err := q.db.ExecContext(ctx, checkRegex, pattern)
+	return err
+}
+
+const createFilterRule = `-- name: CreateFilterRule :one
+INSERT INTO filter_rules (created_at, user_id, feed_id, action, field, match_type, pattern)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, created_at, user_id, feed_id, action, field, match_type, pattern
+`
+
+type CreateFilterRuleParams struct {
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    sql.NullInt32
+	Action    string
+	Field     string
+	MatchType string
+	Pattern   string
+}
+
+func (q *Queries) CreateFilterRule(ctx context.Context, arg CreateFilterRuleParams) (FilterRule, error) {
+	row := q.db.QueryRowContext(ctx, createFilterRule,
+		arg.CreatedAt,
+		arg.UserID,
+		arg.FeedID,
+		arg.Action,
+		arg.Field,
+		arg.MatchType,
+		arg.Pattern,
+	)
+	var i FilterRule
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Action,
+		&i.Field,
+		&i.MatchType,
+		&i.Pattern,
+	)
+	return i, err
+}
+
+const deleteFilterRule = `-- name: DeleteFilterRule :execrows
+DELETE FROM filter_rules
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteFilterRuleParams struct {
+	ID     int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteFilterRule(ctx context.Context, arg DeleteFilterRuleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFilterRule, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getFilterRulesForUser = `-- name: GetFilterRulesForUser :many
+SELECT filter_rules.id, filter_rules.created_at, filter_rules.user_id, filter_rules.feed_id, filter_rules.action, filter_rules.field, filter_rules.match_type, filter_rules.pattern, feed.url AS feed_url
+FROM filter_rules
+LEFT JOIN feed ON feed.id = filter_rules.feed_id
+WHERE filter_rules.user_id = $1
+ORDER BY filter_rules.id
+`
+
+type GetFilterRulesForUserRow struct {
+	ID        int32
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    sql.NullInt32
+	Action    string
+	Field     string
+	MatchType string
+	Pattern   string
+	FeedUrl   sql.NullString
+}
+
+func (q *Queries) GetFilterRulesForUser(ctx context.Context, userID uuid.UUID) ([]GetFilterRulesForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFilterRulesForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFilterRulesForUserRow
+	for rows.Next() {
+		var i GetFilterRulesForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.Action,
+			&i.Field,
+			&i.MatchType,
+			&i.Pattern,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
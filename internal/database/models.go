@@ -11,13 +11,29 @@ import (
 	"github.com/google/uuid"
 )
 
+type ApiToken struct {
+	ID        int32
+	CreatedAt time.Time
+	Name      string
+	TokenHash string
+	UserID    uuid.UUID
+	Scope     string
+}
+
 type Feed struct {
-	ID            int32
-	Name          string
-	Url           string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	LastFetchedAt sql.NullTime
+	ID                  int32
+	Name                string
+	Url                 string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	LastFetchedAt       sql.NullTime
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	NextFetchAt         sql.NullTime
+	LastError           sql.NullString
+	ConsecutiveFailures int32
+	LastSuccessAt       sql.NullTime
+	DisabledAt          sql.NullTime
 }
 
 type FeedFollow struct {
@@ -26,17 +42,86 @@ type FeedFollow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    int32
+	FolderID  sql.NullInt32
+	Title     sql.NullString
+}
+
+type FilterRule struct {
+	ID        int32
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    sql.NullInt32
+	Action    string
+	Field     string
+	MatchType string
+	Pattern   string
+}
+
+type Folder struct {
+	ID        int32
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
 }
 
 type Post struct {
+	ID           int32
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  string
+	PublishedAt  time.Time
+	FeedID       int32
+	SearchVector interface{}
+	Content      string
+	Author       string
+	Categories   []string
+	Guid         string
+	ImageUrl     string
+	DedupKey     string
+	ContentHash  string
+}
+
+type PostEnclosure struct {
+	ID     int32
+	PostID int32
+	Url    string
+	Type   string
+	Length int64
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID int32
+	ReadAt time.Time
+}
+
+type PostRevision struct {
 	ID          int32
+	PostID      int32
 	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ReplacedAt  time.Time
 	Title       string
 	Url         string
 	Description string
-	PublishedAt time.Time
-	FeedID      int32
+	Content     string
+	ContentHash string
+	Author      string
+	Categories  []string
+	ImageUrl    string
+}
+
+type PostStar struct {
+	UserID    uuid.UUID
+	PostID    int32
+	StarredAt time.Time
+}
+
+type PrunedPost struct {
+	FeedID   int32
+	DedupKey string
+	PrunedAt time.Time
 }
 
 type User struct {
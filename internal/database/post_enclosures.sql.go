@@ -0,0 +1,77 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: post_enclosures.sql
+
+package database
+
+import (
+	"context"
+)
+
+const createPostEnclosure = `-- name: CreatePostEnclosure :exec
+INSERT INTO post_enclosures (post_id, url, type, length)
+VALUES ($1, $2, $3, $4)
+`
+
+type CreatePostEnclosureParams struct {
+	PostID int32
+	Url    string
+	Type   string
+	Length int64
+}
+
+func (q *Queries) CreatePostEnclosure(ctx context.Context, arg CreatePostEnclosureParams) error {
+	_, err := q.db.ExecContext(ctx, createPostEnclosure,
+		arg.PostID,
+		arg.Url,
+		arg.Type,
+		arg.Length,
+	)
+	return err
+}
+
+const deletePostEnclosures = `-- name: DeletePostEnclosures :exec
+DELETE FROM post_enclosures
+WHERE post_id = $1
+`
+
+func (q *Queries) DeletePostEnclosures(ctx context.Context, postID int32) error {
+	_, err := q.db.ExecContext(ctx, deletePostEnclosures, postID)
+	return err
+}
+
+const getPostEnclosures = `-- name: GetPostEnclosures :many
+SELECT id, post_id, url, type, length FROM post_enclosures
+WHERE post_id = $1
+ORDER BY id
+`
+
+func (q *Queries) GetPostEnclosures(ctx context.Context, postID int32) ([]PostEnclosure, error) {
+	rows, err := q.db.QueryContext(ctx, getPostEnclosures, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostEnclosure
+	for rows.Next() {
+		var i PostEnclosure
+		if err := rows.Scan(
+			&i.ID,
+			&i.PostID,
+			&i.Url,
+			&i.Type,
+			&i.Length,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
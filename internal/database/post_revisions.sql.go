@@ -0,0 +1,54 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: post_revisions.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+const getPostRevisions = `-- name: GetPostRevisions :many
+SELECT id, post_id, created_at, replaced_at, title, url, description, content, content_hash, author, categories, image_url FROM post_revisions
+WHERE post_id = $1
+ORDER BY replaced_at, id
+`
+
+func (q *Queries) GetPostRevisions(ctx context.Context, postID int32) ([]PostRevision, error) {
+	rows, err := q.db.QueryContext(ctx, getPostRevisions, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostRevision
+	for rows.Next() {
+		var i PostRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.PostID,
+			&i.CreatedAt,
+			&i.ReplacedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.Content,
+			&i.ContentHash,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.ImageUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,27 +7,38 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-const createPost = `-- name: CreatePost :one
-INSERT INTO posts (created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES (
-    $1,
-    $2,
-    $3,
-    $4,
-    $5,
-    $6,
-    $7
-)
-ON CONFLICT (url) DO NOTHING
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+const deleteAllPosts = `-- name: DeleteAllPosts :exec
+DELETE FROM posts
 `
 
-type CreatePostParams struct {
+func (q *Queries) DeleteAllPosts(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllPosts)
+	return err
+}
+
+const findPostForUser = `-- name: FindPostForUser :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id,
+    posts.content, posts.author, posts.categories, posts.guid, posts.image_url, COALESCE(feed_follows.title, feed.name)::varchar AS feed_name
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feed ON feed.id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.id = $2
+`
+
+type FindPostForUserParams struct {
+	UserID uuid.UUID
+	ID     int32
+}
+
+type FindPostForUserRow struct {
+	ID          int32
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	Title       string
@@ -35,19 +46,17 @@ type CreatePostParams struct {
 	Description string
 	PublishedAt time.Time
 	FeedID      int32
+	Content     string
+	Author      string
+	Categories  []string
+	Guid        string
+	ImageUrl    string
+	FeedName    string
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.Title,
-		arg.Url,
-		arg.Description,
-		arg.PublishedAt,
-		arg.FeedID,
-	)
-	var i Post
+func (q *Queries) FindPostForUser(ctx context.Context, arg FindPostForUserParams) (FindPostForUserRow, error) {
+	row := q.db.QueryRowContext(ctx, findPostForUser, arg.UserID, arg.ID)
+	var i FindPostForUserRow
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
@@ -57,42 +66,143 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Content,
+		&i.Author,
+		pq.Array(&i.Categories),
+		&i.Guid,
+		&i.ImageUrl,
+		&i.FeedName,
 	)
 	return i, err
 }
 
-const deleteAllPosts = `-- name: DeleteAllPosts :exec
-DELETE FROM posts
+const getLegacyKeyPosts = `-- name: GetLegacyKeyPosts :many
+SELECT id, feed_id, guid, url FROM posts
+WHERE dedup_key LIKE 'legacy:%'
+ORDER BY id
+LIMIT $1
 `
 
-func (q *Queries) DeleteAllPosts(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, deleteAllPosts)
-	return err
+type GetLegacyKeyPostsRow struct {
+	ID     int32
+	FeedID int32
+	Guid   string
+	Url    string
+}
+
+// Posts saved before deduplication, still keyed by a placeholder (see 014_posts_dedup_key.sql)
+func (q *Queries) GetLegacyKeyPosts(ctx context.Context, limit int32) ([]GetLegacyKeyPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLegacyKeyPosts, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLegacyKeyPostsRow
+	for rows.Next() {
+		var i GetLegacyKeyPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedID,
+			&i.Guid,
+			&i.Url,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, COALESCE(feed_follows.title, feed.name)::varchar AS feed_name, posts.content,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+    ) AS is_read
 FROM posts
 JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feed ON feed.id = posts.feed_id
+LEFT JOIN folders ON folders.id = feed_follows.folder_id
 WHERE feed_follows.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2
+AND (
+    NOT $2::boolean
+    OR NOT EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+    )
+)
+AND ($3::varchar IS NULL OR feed.url = $3)
+AND (
+    $4::varchar IS NULL
+    OR folders.name = $4
+    OR starts_with(folders.name, $4 || '/')
+)
+AND ($5::timestamp IS NULL OR posts.published_at >= $5)
+AND ($6::timestamp IS NULL OR posts.published_at < $6)
+AND ($7::varchar IS NULL OR strpos(lower(posts.author), lower($7)) > 0)
+AND post_passes_filters(feed_follows.user_id, posts.feed_id, posts.title, posts.description, posts.content, posts.author, posts.categories)
+ORDER BY
+    CASE WHEN $8::boolean THEN posts.published_at END ASC,
+    CASE WHEN NOT $8::boolean THEN posts.published_at END DESC,
+    posts.id
+LIMIT $10
+OFFSET $9
 `
 
 type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	UserID      uuid.UUID
+	UnreadOnly  bool
+	FeedUrl     sql.NullString
+	Folder      sql.NullString
+	Since       sql.NullTime
+	Until       sql.NullTime
+	Author      sql.NullString
+	OldestFirst bool
+	Offset      int32
+	Limit       int32
+}
+
+type GetPostsForUserRow struct {
+	ID          int32
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description string
+	PublishedAt time.Time
+	FeedID      int32
+	FeedName    string
+	Content     string
+	IsRead      bool
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+// A folder includes its subfolders
+func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.FeedUrl,
+		arg.Folder,
+		arg.Since,
+		arg.Until,
+		arg.Author,
+		arg.OldestFirst,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items []GetPostsForUserRow
 	for rows.Next() {
-		var i Post
+		var i GetPostsForUserRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
@@ -102,6 +212,9 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.FeedName,
+			&i.Content,
+			&i.IsRead,
 		); err != nil {
 			return nil, err
 		}
@@ -115,3 +228,197 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const getRecentPublishedDatesForFeed = `-- name: GetRecentPublishedDatesForFeed :many
+SELECT published_at FROM posts
+WHERE feed_id = $1 AND published_at > '0001-01-01'
+ORDER BY published_at DESC
+LIMIT $2
+`
+
+type GetRecentPublishedDatesForFeedParams struct {
+	FeedID int32
+	Limit  int32
+}
+
+func (q *Queries) GetRecentPublishedDatesForFeed(ctx context.Context, arg GetRecentPublishedDatesForFeedParams) ([]time.Time, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentPublishedDatesForFeed, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []time.Time
+	for rows.Next() {
+		var published_at time.Time
+		if err := rows.Scan(&published_at); err != nil {
+			return nil, err
+		}
+		items = append(items, published_at)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const mergeLegacyPost = `-- name: MergeLegacyPost :execrows
+WITH duplicate AS (
+    SELECT posts.id, survivor.id AS survivor_id
+    FROM posts
+    JOIN posts AS survivor
+        ON survivor.feed_id = posts.feed_id AND survivor.dedup_key = $1 AND survivor.id <> posts.id
+    WHERE posts.id = $2 AND posts.dedup_key LIKE 'legacy:%'
+), moved_stars AS (
+    INSERT INTO post_stars (user_id, post_id, starred_at)
+    SELECT post_stars.user_id, duplicate.survivor_id, post_stars.starred_at
+    FROM post_stars
+    JOIN duplicate ON duplicate.id = post_stars.post_id
+    ON CONFLICT (user_id, post_id) DO NOTHING
+), moved_reads AS (
+    INSERT INTO post_reads (user_id, post_id, read_at)
+    SELECT post_reads.user_id, duplicate.survivor_id, post_reads.read_at
+    FROM post_reads
+    JOIN duplicate ON duplicate.id = post_reads.post_id
+    ON CONFLICT (user_id, post_id) DO NOTHING
+)
+DELETE FROM posts
+USING duplicate
+WHERE posts.id = duplicate.id
+`
+
+type MergeLegacyPostParams struct {
+	DedupKey string
+	ID       int32
+}
+
+// Removes a post still keyed by a placeholder when another post of its feed has its real key,
+// the stars and reads of the removed post move to the other one. A post rekeyed in the
+// meantime (by another agg or prune) is left alone.
+func (q *Queries) MergeLegacyPost(ctx context.Context, arg MergeLegacyPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, mergeLegacyPost, arg.DedupKey, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setPostDedupKey = `-- name: SetPostDedupKey :execrows
+UPDATE posts SET dedup_key = $1
+WHERE posts.id = $2
+AND NOT EXISTS (
+    SELECT 1 FROM posts AS other
+    WHERE other.feed_id = posts.feed_id AND other.dedup_key = $1 AND other.id <> posts.id
+)
+`
+
+type SetPostDedupKeyParams struct {
+	DedupKey string
+	ID       int32
+}
+
+// Leaves the post alone when another post of its feed already has the key
+func (q *Queries) SetPostDedupKey(ctx context.Context, arg SetPostDedupKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setPostDedupKey, arg.DedupKey, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertPost = `-- name: UpsertPost :one
+WITH previous AS (
+    SELECT id, updated_at, title, url, description, content, author, categories, image_url, content_hash
+    FROM posts
+    WHERE feed_id = $7 AND dedup_key = $13
+), revision AS (
+    INSERT INTO post_revisions (post_id, created_at, replaced_at, title, url, description, content, author, categories, image_url, content_hash)
+    SELECT id, updated_at, $2::timestamp, title, url, description, content, author, categories, image_url, content_hash
+    FROM previous
+    WHERE previous.content_hash <> '' AND previous.content_hash <> $14
+)
+INSERT INTO posts (created_at, updated_at, title, url, description, published_at, feed_id, content, author, categories, guid, image_url, dedup_key, content_hash)
+SELECT
+    $1::timestamp,
+    $2::timestamp,
+    $3::varchar,
+    $4::varchar,
+    $5::varchar,
+    $6::timestamp,
+    $7::integer,
+    $8::varchar,
+    $9::varchar,
+    $10::text[],
+    $11::varchar,
+    $12::varchar,
+    $13::varchar,
+    $14::varchar
+WHERE NOT EXISTS (
+    SELECT 1 FROM pruned_posts
+    WHERE pruned_posts.feed_id = $7 AND pruned_posts.dedup_key = $13
+)
+ON CONFLICT (feed_id, dedup_key) DO UPDATE SET
+    updated_at = EXCLUDED.updated_at,
+    title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    content = EXCLUDED.content,
+    author = EXCLUDED.author,
+    categories = EXCLUDED.categories,
+    image_url = EXCLUDED.image_url,
+    content_hash = EXCLUDED.content_hash
+WHERE posts.content_hash <> EXCLUDED.content_hash
+RETURNING id, (xmax = 0)::boolean AS inserted,
+    EXISTS (SELECT 1 FROM previous WHERE previous.content_hash <> '') AS revised
+`
+
+type UpsertPostParams struct {
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description string
+	PublishedAt time.Time
+	FeedID      int32
+	Content     string
+	Author      string
+	Categories  []string
+	Guid        string
+	ImageUrl    string
+	DedupKey    string
+	ContentHash string
+}
+
+type UpsertPostRow struct {
+	ID       int32
+	Inserted bool
+	Revised  bool
+}
+
+// The version being replaced is kept in post_revisions, unless it predates content hashes.
+// Such a post only gets its hash: it's not revised, it was saved before gator could tell.
+// Returns no row when the post didn't change or was pruned.
+// Pruned posts still in the feed stay pruned
+func (q *Queries) UpsertPost(ctx context.Context, arg UpsertPostParams) (UpsertPostRow, error) {
+	row := q.db.QueryRowContext(ctx, upsertPost,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.FeedID,
+		arg.Content,
+		arg.Author,
+		pq.Array(arg.Categories),
+		arg.Guid,
+		arg.ImageUrl,
+		arg.DedupKey,
+		arg.ContentHash,
+	)
+	var i UpsertPostRow
+	err := row.Scan(&i.ID, &i.Inserted, &i.Revised)
+	return i, err
+}
@@ -0,0 +1,125 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: prune.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const forgetPrunedPosts = `-- name: ForgetPrunedPosts :exec
+DELETE FROM pruned_posts
+WHERE feed_id = $1 AND NOT (dedup_key = ANY($2::varchar[]))
+`
+
+type ForgetPrunedPostsParams struct {
+	FeedID int32
+	Keys   []string
+}
+
+// A pruned post no longer listed by its feed can't be inserted again, so it doesn't need a tombstone
+func (q *Queries) ForgetPrunedPosts(ctx context.Context, arg ForgetPrunedPostsParams) error {
+	_, err := q.db.ExecContext(ctx, forgetPrunedPosts, arg.FeedID, pq.Array(arg.Keys))
+	return err
+}
+
+const prunePosts = `-- name: PrunePosts :many
+WITH ranked AS (
+    SELECT posts.id, posts.feed_id, posts.dedup_key, posts.published_at, posts.created_at,
+        ROW_NUMBER() OVER (PARTITION BY posts.feed_id ORDER BY posts.published_at DESC, posts.id DESC) AS position
+    FROM posts
+), doomed AS (
+    SELECT ranked.id, ranked.feed_id, ranked.dedup_key
+    FROM ranked
+    WHERE (
+        -- Posts without a publish date are aged from when gator saved them
+        (
+            $1::timestamp IS NOT NULL
+            AND CASE WHEN ranked.published_at > '0001-01-01' THEN ranked.published_at ELSE ranked.created_at END < $1
+        )
+        OR ($2::integer > 0 AND ranked.position > $2::integer)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM post_stars
+        WHERE post_stars.post_id = ranked.id
+    )
+    AND (
+        NOT $3::boolean
+        OR NOT EXISTS (
+            SELECT 1 FROM feed_follows
+            WHERE feed_follows.feed_id = ranked.feed_id
+            AND NOT EXISTS (
+                SELECT 1 FROM post_reads
+                WHERE post_reads.post_id = ranked.id AND post_reads.user_id = feed_follows.user_id
+            )
+        )
+    )
+), deleted AS (
+    DELETE FROM posts
+    USING doomed
+    WHERE posts.id = doomed.id AND NOT $4::boolean
+    RETURNING posts.feed_id, posts.dedup_key
+), tombstones AS (
+    INSERT INTO pruned_posts (feed_id, dedup_key, pruned_at)
+    SELECT deleted.feed_id, deleted.dedup_key, $5::timestamp
+    FROM deleted
+    ON CONFLICT (feed_id, dedup_key) DO NOTHING
+)
+SELECT feed.id, feed.name, COUNT(*) AS removed
+FROM doomed
+JOIN feed ON feed.id = doomed.feed_id
+GROUP BY feed.id, feed.name
+ORDER BY removed DESC, feed.name
+`
+
+type PrunePostsParams struct {
+	OlderThan  sql.NullTime
+	MaxPosts   int32
+	KeepUnread bool
+	DryRun     bool
+	Now        time.Time
+}
+
+type PrunePostsRow struct {
+	ID      int32
+	Name    string
+	Removed int64
+}
+
+// Removes the posts older than older_than, or beyond the max_posts most recent of their feed
+// (0 for no limit). Starred posts are always kept, unread ones if keep_unread. With dry_run
+// nothing is removed. Returns the number of posts removed per feed.
+func (q *Queries) PrunePosts(ctx context.Context, arg PrunePostsParams) ([]PrunePostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, prunePosts,
+		arg.OlderThan,
+		arg.MaxPosts,
+		arg.KeepUnread,
+		arg.DryRun,
+		arg.Now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PrunePostsRow
+	for rows.Next() {
+		var i PrunePostsRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Removed); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,85 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: search.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const searchPostsForUser = `-- name: SearchPostsForUser :many
+SELECT posts.id, posts.title, posts.url, posts.published_at, COALESCE(feed_follows.title, feed.name)::varchar AS feed_name,
+    ts_rank(posts.search_vector, websearch_to_tsquery('english', $1)) AS rank
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feed ON feed.id = posts.feed_id
+WHERE feed_follows.user_id = $2
+AND posts.search_vector @@ websearch_to_tsquery('english', $1)
+AND ($3::varchar IS NULL OR feed.url = $3)
+AND ($4::timestamp IS NULL OR posts.published_at >= $4)
+AND ($5::timestamp IS NULL OR posts.published_at < $5)
+AND post_passes_filters(feed_follows.user_id, posts.feed_id, posts.title, posts.description, posts.content, posts.author, posts.categories)
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT $6
+`
+
+type SearchPostsForUserParams struct {
+	Query      string
+	UserID     uuid.UUID
+	FeedUrl    sql.NullString
+	Since      sql.NullTime
+	Until      sql.NullTime
+	MaxResults int32
+}
+
+type SearchPostsForUserRow struct {
+	ID          int32
+	Title       string
+	Url         string
+	PublishedAt time.Time
+	FeedName    string
+	Rank        float32
+}
+
+func (q *Queries) SearchPostsForUser(ctx context.Context, arg SearchPostsForUserParams) ([]SearchPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPostsForUser,
+		arg.Query,
+		arg.UserID,
+		arg.FeedUrl,
+		arg.Since,
+		arg.Until,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsForUserRow
+	for rows.Next() {
+		var i SearchPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FeedName,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
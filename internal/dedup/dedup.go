@@ -0,0 +1,81 @@
+package dedup
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters added by newsletters and analytics, they don't change the page
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+}
+
+// Key identifies an item within its feed: its guid when it has one, else its normalized link.
+// Guids that are urls (RSS permalinks) are normalized too, so http→https moves don't duplicate posts.
+func Key(guid, link string) string {
+	guid = strings.TrimSpace(guid)
+	if guid != "" {
+		if isWebURL(guid) {
+			return "guid:" + NormalizeURL(guid)
+		}
+		return "guid:" + guid
+	}
+
+	return "url:" + NormalizeURL(link)
+}
+
+// NormalizeURL reduces the variants of a web page url to one form: no scheme, lowercase host
+// without www. and default port, no fragment, no trailing slash and no tracking parameters.
+// Strings that are not absolute http(s) urls are only trimmed.
+func NormalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+
+	u, err := url.Parse(raw)
+	if err != nil || !isWebURL(raw) {
+		return raw
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+
+	port := u.Port()
+	if port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+
+	query := u.Query()
+	for key := range query {
+		if trackingParams[strings.ToLower(key)] || strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+
+	normalized := "//" + host + path
+	if len(query) > 0 {
+		// Encode sorts the parameters by key
+		normalized += "?" + query.Encode()
+	}
+
+	return normalized
+}
+
+func isWebURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	return scheme == "http" || scheme == "https"
+}
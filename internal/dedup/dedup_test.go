@@ -0,0 +1,65 @@
+package dedup
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"scheme and www", "https://www.Example.com/post", "//example.com/post"},
+		{"http and https match", "http://example.com/post", "//example.com/post"},
+		{"default ports", "https://example.com:443/post", "//example.com/post"},
+		{"other ports kept", "http://example.com:8080/post", "//example.com:8080/post"},
+		{"trailing slash", "https://example.com/post/", "//example.com/post"},
+		{"root", "https://example.com/", "//example.com"},
+		{"fragment", "https://example.com/post#comments", "//example.com/post"},
+		{"tracking params", "https://example.com/post?utm_source=rss&UTM_medium=feed&fbclid=abc", "//example.com/post"},
+		{"params sorted", "https://example.com/post?b=2&a=1&utm_campaign=x", "//example.com/post?a=1&b=2"},
+		{"path case kept", "https://example.com/Post", "//example.com/Post"},
+		{"spaces", "  https://example.com/post  ", "//example.com/post"},
+		{"not a web url", "ftp://example.com/file", "ftp://example.com/file"},
+		{"relative", "/post", "/post"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeURL(tt.raw)
+			if got != tt.want {
+				t.Errorf("NormalizeURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		guid string
+		link string
+		want string
+	}{
+		{"guid", "tag:example.com,2024:1", "https://example.com/post", "guid:tag:example.com,2024:1"},
+		{"guid trimmed", "  abc  ", "", "guid:abc"},
+		{"guid url normalized", "http://www.example.com/post/", "", "guid://example.com/post"},
+		{"link without guid", "", "https://example.com/post?utm_source=rss", "url://example.com/post"},
+		{"blank guid", "   ", "https://example.com/post", "url://example.com/post"},
+		{"nothing", "", "", "url:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Key(tt.guid, tt.link)
+			if got != tt.want {
+				t.Errorf("Key(%q, %q) = %q, want %q", tt.guid, tt.link, got, tt.want)
+			}
+		})
+	}
+
+	// A guid wins over the link, so a post whose link changed keeps its key
+	if Key("abc", "https://example.com/a") != Key("abc", "https://example.com/b") {
+		t.Error("Key changed with the link of a post with a guid")
+	}
+}
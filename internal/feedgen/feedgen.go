@@ -0,0 +1,246 @@
+package feedgen
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatAtom     Format = "atom"
+	FormatRSS      Format = "rss"
+	FormatJSONFeed Format = "jsonfeed"
+)
+
+// ContentTypes maps each format to the Content-Type it should be served with
+var ContentTypes = map[Format]string{
+	FormatAtom:     "application/atom+xml; charset=utf-8",
+	FormatRSS:      "application/rss+xml; charset=utf-8",
+	FormatJSONFeed: "application/feed+json; charset=utf-8",
+}
+
+func ParseFormat(s string) (Format, error) {
+	format := Format(s)
+	if _, ok := ContentTypes[format]; !ok {
+		return "", fmt.Errorf("unknown format '%s', expected atom, rss or jsonfeed", s)
+	}
+	return format, nil
+}
+
+// River is a merged feed built from posts of several source feeds
+type River struct {
+	ID      string
+	Title   string
+	Link    string
+	Updated time.Time
+	Items   []Item
+}
+
+type Item struct {
+	ID          string
+	Title       string
+	URL         string
+	Description string
+	Published   time.Time
+	Source      string // name of the feed the item comes from
+}
+
+func Write(w io.Writer, format Format, river River) error {
+	switch format {
+	case FormatAtom:
+		return writeXML(w, toAtom(river))
+	case FormatRSS:
+		return writeXML(w, toRSS(river))
+	case FormatJSONFeed:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(toJSONFeed(river))
+	default:
+		return fmt.Errorf("unknown format '%s'", format)
+	}
+}
+
+func writeXML(w io.Writer, doc any) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return fmt.Errorf("err writing header: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(doc)
+	if err != nil {
+		return fmt.Errorf("err encoding feed: %w", err)
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// Atom 1.0, RFC 4287
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published"`
+	Link      atomLink    `xml:"link"`
+	Author    atomAuthor  `xml:"author"`
+	Summary   atomSummary `xml:"summary"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomSummary struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+func toAtom(river River) atomFeed {
+	feed := atomFeed{
+		ID:      river.ID,
+		Title:   river.Title,
+		Updated: river.Updated.UTC().Format(time.RFC3339),
+	}
+
+	if river.Link != "" {
+		feed.Links = append(feed.Links, atomLink{Href: river.Link, Rel: "self"})
+	}
+
+	for _, item := range river.Items {
+		published := item.Published.UTC().Format(time.RFC3339)
+
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Updated:   published,
+			Published: published,
+			Link:      atomLink{Href: item.URL},
+			Author:    atomAuthor{Name: item.Source},
+			Summary:   atomSummary{Type: "html", Text: item.Description},
+		})
+	}
+
+	return feed
+}
+
+// RSS 2.0
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Source      string  `xml:"category,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func toRSS(river River) rssFeed {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         river.Title,
+			Link:          river.Link,
+			Description:   river.Title,
+			LastBuildDate: river.Updated.UTC().Format(time.RFC1123Z),
+		},
+	}
+
+	for _, item := range river.Items {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.URL,
+			Description: item.Description,
+			GUID:        rssGUID{IsPermaLink: item.ID == item.URL, Value: item.ID},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Source:      item.Source,
+		})
+	}
+
+	return feed
+}
+
+// JSON Feed 1.1, https://www.jsonfeed.org/version/1.1/
+
+type jsonFeed struct {
+	Version string         `json:"version"`
+	Title   string         `json:"title"`
+	FeedURL string         `json:"feed_url,omitempty"`
+	Items   []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html"`
+	DatePublished string           `json:"date_published"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+func toJSONFeed(river River) jsonFeed {
+	feed := jsonFeed{
+		Version: "https://jsonfeed.org/version/1.1",
+		Title:   river.Title,
+		FeedURL: river.Link,
+		Items:   make([]jsonFeedItem, 0, len(river.Items)),
+	}
+
+	for _, item := range river.Items {
+		feed.Items = append(feed.Items, jsonFeedItem{
+			ID:            item.ID,
+			URL:           item.URL,
+			Title:         item.Title,
+			ContentHTML:   item.Description,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			Authors:       []jsonFeedAuthor{{Name: item.Source}},
+		})
+	}
+
+	return feed
+}
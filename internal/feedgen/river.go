@@ -0,0 +1,38 @@
+package feedgen
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+)
+
+// NewRiver builds the river of a user from the posts of the feeds they follow, newest first.
+// link is where the river is served from, if anywhere. Items are identified by their post,
+// their url can be empty or shared by posts of several feeds.
+func NewRiver(user database.User, posts []database.GetPostsForUserRow, link string) River {
+	river := River{
+		ID:      "urn:uuid:" + user.ID.String(),
+		Title:   "gator river of " + user.Name,
+		Link:    link,
+		Updated: time.Now().UTC(),
+		Items:   make([]Item, 0, len(posts)),
+	}
+
+	if len(posts) > 0 {
+		river.Updated = posts[0].PublishedAt
+	}
+
+	for _, post := range posts {
+		river.Items = append(river.Items, Item{
+			ID:          fmt.Sprintf("urn:gator:post:%d", post.ID),
+			Title:       post.Title,
+			URL:         post.Url,
+			Description: post.Description,
+			Published:   post.PublishedAt,
+			Source:      post.FeedName,
+		})
+	}
+
+	return river
+}
@@ -0,0 +1,162 @@
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+	"time"
+)
+
+// OPML is the subset of the OPML 2.0 format readers use to exchange subscription lists
+type OPML struct {
+	XMLName xml.Name  `xml:"opml"`
+	Version string    `xml:"version,attr"`
+	Head    Head      `xml:"head"`
+	Body    []Outline `xml:"body>outline"`
+}
+
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+	OwnerName   string `xml:"ownerName,omitempty"`
+}
+
+// Outline is either a subscription (XMLURL is set) or a folder holding other outlines
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Subscription is a feed found in an OPML document, Category is the path of the
+// folders it was nested in, joined by "/" (empty when at the top level)
+type Subscription struct {
+	Name     string
+	URL      string
+	Category string
+	// Renamed maps the folders of Category that had a "/" in their name to that name
+	Renamed map[string]string
+}
+
+// folderSlash replaces a "/" in the name of a folder, which would read as a subfolder in a category
+const folderSlash = "-"
+
+func Parse(r io.Reader) (*OPML, error) {
+	var doc OPML
+
+	err := xml.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("err decoding opml: %w", err)
+	}
+
+	return &doc, nil
+}
+
+// Subscriptions flattens the outline tree into the list of feeds it contains
+func (o *OPML) Subscriptions() []Subscription {
+	var subs []Subscription
+	collect(o.Body, nil, nil, &subs)
+	return subs
+}
+
+func collect(outlines []Outline, folders []string, renamed map[string]string, subs *[]Subscription) {
+	for _, outline := range outlines {
+		name := outline.Title
+		if name == "" {
+			name = outline.Text
+		}
+
+		if outline.XMLURL == "" {
+			// Not a feed, so it's a folder
+			folder := strings.ReplaceAll(name, "/", folderSlash)
+			if folder != name {
+				renamed = maps.Clone(renamed)
+				if renamed == nil {
+					renamed = make(map[string]string)
+				}
+				renamed[folder] = name
+			}
+
+			collect(outline.Outlines, append(folders, folder), renamed, subs)
+			continue
+		}
+
+		if name == "" {
+			name = outline.XMLURL
+		}
+
+		*subs = append(*subs, Subscription{
+			Name:     name,
+			URL:      outline.XMLURL,
+			Category: strings.Join(folders, "/"),
+			Renamed:  renamed,
+		})
+	}
+}
+
+// New builds an OPML document from subscriptions, categories become (nested) folders
+func New(title string, owner string, subs []Subscription) *OPML {
+	doc := &OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: time.Now().UTC().Format(time.RFC1123),
+			OwnerName:   owner,
+		},
+	}
+
+	for _, sub := range subs {
+		outline := Outline{
+			Text:   sub.Name,
+			Title:  sub.Name,
+			Type:   "rss",
+			XMLURL: sub.URL,
+		}
+
+		outlines := &doc.Body
+		if sub.Category != "" {
+			for _, folder := range strings.Split(sub.Category, "/") {
+				outlines = &findOrAddFolder(outlines, folder).Outlines
+			}
+		}
+
+		*outlines = append(*outlines, outline)
+	}
+
+	return doc
+}
+
+func findOrAddFolder(outlines *[]Outline, name string) *Outline {
+	for i := range *outlines {
+		if (*outlines)[i].XMLURL == "" && (*outlines)[i].Text == name {
+			return &(*outlines)[i]
+		}
+	}
+
+	*outlines = append(*outlines, Outline{Text: name, Title: name})
+
+	return &(*outlines)[len(*outlines)-1]
+}
+
+func (o *OPML) Write(w io.Writer) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return fmt.Errorf("err writing header: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(o)
+	if err != nil {
+		return fmt.Errorf("err encoding opml: %w", err)
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
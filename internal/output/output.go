@@ -0,0 +1,290 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatCSV    Format = "csv"
+	FormatTSV    Format = "tsv"
+)
+
+var formats = []Format{FormatText, FormatJSON, FormatNDJSON, FormatCSV, FormatTSV}
+
+// Names lists the formats --output accepts
+func Names() []string {
+	names := make([]string, 0, len(formats))
+	for _, format := range formats {
+		names = append(names, string(format))
+	}
+	return names
+}
+
+func ParseFormat(s string) (Format, error) {
+	for _, format := range formats {
+		if Format(s) == format {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format '%s', expected text, json, ndjson, csv or tsv", s)
+}
+
+// ExtractFlag removes the global --output option from the command line arguments, wherever it is
+// before --, and returns the format it asks for (text by default)
+func ExtractFlag(args []string) (Format, []string, error) {
+	format := FormatText
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		var value string
+		switch {
+		case arg == "--":
+			// What follows is positional, even an --output, the command sees the -- too
+			return format, append(rest, args[i:]...), nil
+		case arg == "--output" || arg == "-output":
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("--output needs a value: text, json, ndjson, csv or tsv")
+			}
+			i++
+			value = args[i]
+		case strings.HasPrefix(arg, "--output="):
+			value = strings.TrimPrefix(arg, "--output=")
+		case strings.HasPrefix(arg, "-output="):
+			value = strings.TrimPrefix(arg, "-output=")
+		default:
+			rest = append(rest, arg)
+			continue
+		}
+
+		parsed, err := ParseFormat(value)
+		if err != nil {
+			return "", nil, err
+		}
+		format = parsed
+	}
+
+	return format, rest, nil
+}
+
+// Printer writes the results of a command in the output format chosen by the user.
+// Records are structs, their json tags name the fields in every format.
+type Printer struct {
+	Format Format
+	Out    io.Writer
+	// Messages receives the progress and confirmation messages, they go to stderr
+	// in the machine readable formats so stdout only holds records
+	Messages io.Writer
+	// Errors receives the errors a command reports and goes on after, always on stderr
+	Errors io.Writer
+}
+
+func NewPrinter(format Format) *Printer {
+	p := &Printer{Format: format, Out: os.Stdout, Messages: os.Stdout, Errors: os.Stderr}
+	if format != FormatText {
+		p.Messages = os.Stderr
+	}
+	return p
+}
+
+// IsText tells if the output is for humans
+func (p *Printer) IsText() bool {
+	return p.Format == FormatText
+}
+
+// Info prints a message for the user, not part of the results of the command
+func (p *Printer) Info(format string, args ...any) {
+	fmt.Fprintf(p.Messages, format, args...)
+}
+
+// Error reports an error the command doesn't stop on, like a feed that couldn't be fetched
+func (p *Printer) Error(err error) {
+	fmt.Fprintln(p.Errors, err)
+}
+
+// List prints a slice of records, text is called instead in the text format
+func (p *Printer) List(records any, text func()) error {
+	if p.IsText() {
+		text()
+		return nil
+	}
+
+	rows := reflect.ValueOf(records)
+	if rows.Kind() != reflect.Slice {
+		return fmt.Errorf("output list needs a slice, got %T", records)
+	}
+
+	switch p.Format {
+	case FormatJSON:
+		// An empty list is [] and not null
+		if rows.Len() == 0 {
+			records = []struct{}{}
+		}
+		return p.writeJSON(records, "  ")
+
+	case FormatNDJSON:
+		for i := range rows.Len() {
+			err := p.writeJSON(rows.Index(i).Interface(), "")
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return p.writeTable(rows)
+	}
+}
+
+// Item prints a single record, text is called instead in the text format
+func (p *Printer) Item(record any, text func()) error {
+	if p.IsText() {
+		text()
+		return nil
+	}
+
+	switch p.Format {
+	case FormatJSON:
+		return p.writeJSON(record, "  ")
+	case FormatNDJSON:
+		return p.writeJSON(record, "")
+	default:
+		rows := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(record)), 0, 1)
+		rows = reflect.Append(rows, reflect.ValueOf(record))
+		return p.writeTable(rows)
+	}
+}
+
+func (p *Printer) writeJSON(value any, indent string) error {
+	encoder := json.NewEncoder(p.Out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+
+	err := encoder.Encode(value)
+	if err != nil {
+		return fmt.Errorf("err writing json: %w", err)
+	}
+	return nil
+}
+
+// writeTable writes records as csv or tsv, with a header line of their json field names
+func (p *Printer) writeTable(rows reflect.Value) error {
+	recordType := rows.Type().Elem()
+	for recordType.Kind() == reflect.Pointer {
+		recordType = recordType.Elem()
+	}
+	if recordType.Kind() != reflect.Struct {
+		return fmt.Errorf("output table needs structs, got %s", recordType)
+	}
+
+	var header []string
+	var fields []int
+
+	for i := range recordType.NumField() {
+		field := recordType.Field(i)
+		name := columnName(field)
+		if name == "" {
+			continue
+		}
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+
+	lines := [][]string{header}
+
+	for i := range rows.Len() {
+		row := reflect.Indirect(rows.Index(i))
+
+		line := make([]string, 0, len(fields))
+		for _, field := range fields {
+			line = append(line, cell(row.Field(field)))
+		}
+		lines = append(lines, line)
+	}
+
+	if p.Format == FormatTSV {
+		for _, line := range lines {
+			for i := range line {
+				line[i] = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(line[i])
+			}
+			_, err := fmt.Fprintln(p.Out, strings.Join(line, "\t"))
+			if err != nil {
+				return fmt.Errorf("err writing tsv: %w", err)
+			}
+		}
+		return nil
+	}
+
+	w := csv.NewWriter(p.Out)
+	err := w.WriteAll(lines)
+	if err != nil {
+		return fmt.Errorf("err writing csv: %w", err)
+	}
+	return nil
+}
+
+func columnName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// cell formats a value for a csv or tsv column: times as RFC3339, lists joined with ;
+// and anything more complex as json
+func cell(value reflect.Value) string {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	if t, ok := value.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	switch value.Kind() {
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.String {
+			items := make([]string, 0, value.Len())
+			for i := range value.Len() {
+				items = append(items, value.Index(i).String())
+			}
+			return strings.Join(items, ";")
+		}
+		fallthrough
+	case reflect.Struct, reflect.Map:
+		encoded, err := json.Marshal(value.Interface())
+		if err != nil {
+			return fmt.Sprint(value.Interface())
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
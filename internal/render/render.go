@@ -0,0 +1,293 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLToText renders the HTML of a post as plain text for a terminal of the given width.
+// Paragraphs are separated by blank lines, lists keep their bullets or numbers, and links are
+// turned into [n] footnotes listed at the end. A width of 0 or less disables wrapping.
+func HTMLToText(src string, width int) string {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		// The parser only fails on read errors, which a strings.Reader doesn't have
+		return src
+	}
+
+	r := &renderer{width: width}
+	r.walk(doc)
+	r.flush()
+
+	text := r.out.String()
+
+	if len(r.links) > 0 {
+		notes := make([]string, 0, len(r.links))
+		for i, link := range r.links {
+			notes = append(notes, fmt.Sprintf("[%d] %s", i+1, link))
+		}
+		text += "\n\n" + strings.Join(notes, "\n")
+	}
+
+	return text
+}
+
+type list struct {
+	ordered bool
+	next    int
+}
+
+type renderer struct {
+	out   strings.Builder
+	width int
+
+	// inline is the text of the block being built
+	inline strings.Builder
+	// marker is the bullet or number of a list item, printed on its first line only
+	marker string
+
+	lists  []list
+	quotes int
+	pre    int
+
+	links []string
+
+	// lastInList tells whether the previous block was part of a list, so items are not spaced out
+	lastInList bool
+}
+
+func (r *renderer) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		r.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Noscript:
+		return
+
+	case atom.Br:
+		r.inline.WriteString("\n")
+
+	case atom.Hr:
+		r.flush()
+		r.block("────────", false)
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		r.flush()
+		level, _ := strconv.Atoi(n.Data[1:])
+		r.inline.WriteString(strings.Repeat("#", level) + " ")
+		r.children(n)
+		r.flush()
+
+	case atom.Ul, atom.Ol:
+		r.flush()
+		l := list{ordered: n.DataAtom == atom.Ol, next: 1}
+		if start, err := strconv.Atoi(Attr(n, "start")); err == nil {
+			l.next = start
+		}
+		r.lists = append(r.lists, l)
+		r.children(n)
+		r.flush()
+		r.lists = r.lists[:len(r.lists)-1]
+		if len(r.lists) == 0 {
+			r.lastInList = false
+		}
+
+	case atom.Li:
+		r.flush()
+		r.marker = "• "
+		if len(r.lists) > 0 {
+			l := &r.lists[len(r.lists)-1]
+			if l.ordered {
+				r.marker = fmt.Sprintf("%d. ", l.next)
+				l.next++
+			}
+		}
+		r.children(n)
+		r.flush()
+
+	case atom.Blockquote:
+		r.flush()
+		r.quotes++
+		r.children(n)
+		r.flush()
+		r.quotes--
+
+	case atom.Pre:
+		r.flush()
+		r.pre++
+		r.children(n)
+		r.flush()
+		r.pre--
+
+	case atom.A:
+		r.children(n)
+		href := strings.TrimSpace(Attr(n, "href"))
+		if href != "" && !strings.HasPrefix(href, "#") && !strings.HasPrefix(href, "javascript:") {
+			r.links = append(r.links, href)
+			fmt.Fprintf(&r.inline, " [%d]", len(r.links))
+		}
+
+	case atom.Img:
+		if alt := strings.TrimSpace(Attr(n, "alt")); alt != "" {
+			r.text("[image: " + alt + "]")
+		}
+
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Figure,
+		atom.Figcaption, atom.Table, atom.Tr, atom.Dl, atom.Dt, atom.Dd, atom.Details, atom.Summary:
+		r.flush()
+		r.children(n)
+		r.flush()
+
+	case atom.Td, atom.Th:
+		r.children(n)
+		r.inline.WriteString("  ")
+
+	default:
+		r.children(n)
+	}
+}
+
+func (r *renderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c)
+	}
+}
+
+// text adds inline text, collapsing whitespace the way a browser does outside of <pre>
+func (r *renderer) text(s string) {
+	if r.pre > 0 {
+		r.inline.WriteString(s)
+		return
+	}
+
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		if s != "" {
+			r.space()
+		}
+		return
+	}
+
+	if s[0] == ' ' || s[0] == '\t' || s[0] == '\n' || s[0] == '\r' {
+		r.space()
+	}
+
+	r.inline.WriteString(strings.Join(words, " "))
+
+	last := s[len(s)-1]
+	if last == ' ' || last == '\t' || last == '\n' || last == '\r' {
+		r.space()
+	}
+}
+
+func (r *renderer) space() {
+	current := r.inline.String()
+	if current != "" && !strings.HasSuffix(current, " ") && !strings.HasSuffix(current, "\n") {
+		r.inline.WriteString(" ")
+	}
+}
+
+// flush ends the block being built and writes it wrapped and indented to the output
+func (r *renderer) flush() {
+	text := r.inline.String()
+	r.inline.Reset()
+
+	if r.pre > 0 {
+		text = strings.Trim(text, "\n")
+	} else {
+		lines := strings.Split(text, "\n")
+		for i := range lines {
+			lines[i] = strings.TrimSpace(lines[i])
+		}
+		text = strings.TrimSpace(strings.Join(lines, "\n"))
+	}
+
+	if text == "" {
+		return
+	}
+
+	r.block(text, len(r.lists) > 0)
+}
+
+func (r *renderer) block(text string, inList bool) {
+	quote := strings.Repeat("> ", r.quotes)
+	indent := strings.Repeat("  ", max(len(r.lists)-1, 0))
+
+	first := quote + indent + r.marker
+	rest := quote + indent + strings.Repeat(" ", utf8.RuneCountInString(r.marker))
+	r.marker = ""
+
+	var lines []string
+	if r.pre > 0 {
+		lines = strings.Split(text, "\n")
+	} else {
+		lines = wrap(text, r.width-utf8.RuneCountInString(first))
+	}
+
+	if r.out.Len() > 0 {
+		if inList && r.lastInList {
+			r.out.WriteString("\n")
+		} else {
+			r.out.WriteString("\n\n")
+		}
+	}
+	r.lastInList = inList
+
+	for i, line := range lines {
+		if i == 0 {
+			r.out.WriteString(first + line)
+		} else {
+			r.out.WriteString("\n" + rest + line)
+		}
+	}
+}
+
+// wrap splits text into lines of at most width characters, breaking between words.
+// Words longer than width (usually urls) get a line of their own.
+func wrap(text string, width int) []string {
+	var lines []string
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		if width <= 0 {
+			lines = append(lines, paragraph)
+			continue
+		}
+
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// Attr returns the value of the attribute key of n, or "" when n doesn't have it
+func Attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,161 @@
+package requests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Ciobi0212/gator.git/internal/render"
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxPageSize bounds how much of a web page is read when looking for its feeds
+const maxPageSize = 5 << 20
+
+// feedTypes are the <link rel="alternate"> types announcing a feed
+var feedTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	// JSON Feed's first version announced itself as plain json
+	"application/json": true,
+}
+
+// commonFeedPaths are tried when a page doesn't announce its feeds
+var commonFeedPaths = []string{"/feed", "/rss.xml", "/atom.xml", "/feed.xml", "/index.xml"}
+
+// Candidate is a feed found by DiscoverFeeds. Title is the one of the link or of the feed,
+// and may be empty.
+type Candidate struct {
+	URL   string
+	Title string
+}
+
+// DiscoverFeeds finds the feeds of a website. A page listing its feeds with
+// <link rel="alternate"> tags gives those, otherwise the usual feed paths of the site are tried.
+// If siteURL already is a feed, it is the only candidate.
+func DiscoverFeeds(ctx context.Context, siteURL string) ([]Candidate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, siteURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error doing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("error reading page: %w", err)
+	}
+
+	// Redirects are followed, links are relative to where we ended up
+	base := resp.Request.URL
+
+	if gofeed.DetectFeedType(bytes.NewReader(body)) != gofeed.FeedTypeUnknown {
+		title := ""
+		if feed, err := fp.Parse(bytes.NewReader(body)); err == nil {
+			title = feed.Title
+		}
+		return []Candidate{{URL: base.String(), Title: title}}, nil
+	}
+
+	candidates, err := alternateLinks(body, base)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candidates) > 0 {
+		return candidates, nil
+	}
+
+	for _, path := range commonFeedPaths {
+		feedURL := base.ResolveReference(&url.URL{Path: path}).String()
+
+		result, err := FetchFeed(ctx, feedURL, Validators{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+
+		candidates = append(candidates, Candidate{URL: feedURL, Title: result.Feed.Title})
+	}
+
+	return candidates, nil
+}
+
+// alternateLinks returns the feeds announced in the <link rel="alternate"> tags of a page
+func alternateLinks(page []byte, base *url.URL) ([]Candidate, error) {
+	doc, err := html.Parse(bytes.NewReader(page))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing page: %w", err)
+	}
+
+	var candidates []Candidate
+	seen := make(map[string]bool)
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Base {
+			if href, err := url.Parse(render.Attr(n, "href")); err == nil && render.Attr(n, "href") != "" {
+				base = base.ResolveReference(href)
+			}
+		}
+
+		if n.Type == html.ElementNode && n.DataAtom == atom.Link && isFeedLink(n) {
+			href, err := url.Parse(strings.TrimSpace(render.Attr(n, "href")))
+			if err == nil {
+				feedURL := base.ResolveReference(href).String()
+				if !seen[feedURL] {
+					seen[feedURL] = true
+					candidates = append(candidates, Candidate{URL: feedURL, Title: strings.TrimSpace(render.Attr(n, "title"))})
+				}
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return candidates, nil
+}
+
+func isFeedLink(n *html.Node) bool {
+	if render.Attr(n, "href") == "" {
+		return false
+	}
+
+	kind := strings.ToLower(strings.TrimSpace(render.Attr(n, "type")))
+	if i := strings.Index(kind, ";"); i >= 0 {
+		kind = strings.TrimSpace(kind[:i])
+	}
+
+	for _, rel := range strings.Fields(strings.ToLower(render.Attr(n, "rel"))) {
+		if rel == "alternate" {
+			return feedTypes[kind]
+		}
+	}
+
+	return false
+}
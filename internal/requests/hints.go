@@ -0,0 +1,133 @@
+package requests
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/rss"
+)
+
+// Hints are what the publisher tells us about how often the feed should be polled.
+// Zero values mean the publisher did not say anything.
+type Hints struct {
+	TTL        time.Duration // RSS <ttl> or sy:updatePeriod / sy:updateFrequency
+	MaxAge     time.Duration // Cache-Control max-age
+	RetryAfter time.Time     // Retry-After
+}
+
+// rssTranslator keeps the channel <ttl>, which the default translator drops
+type rssTranslator struct {
+	gofeed.DefaultRSSTranslator
+}
+
+func (t *rssTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultRSSTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	if rssFeed, ok := feed.(*rss.Feed); ok && rssFeed.TTL != "" {
+		if result.Custom == nil {
+			result.Custom = map[string]string{}
+		}
+		result.Custom["ttl"] = rssFeed.TTL
+	}
+
+	return result, nil
+}
+
+func hintsFromResponse(resp *http.Response, now time.Time) Hints {
+	return Hints{
+		MaxAge:     parseMaxAge(resp.Header.Get("Cache-Control")),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), now),
+	}
+}
+
+func ttlFromFeed(feed *gofeed.Feed) time.Duration {
+	if ttl, ok := feed.Custom["ttl"]; ok {
+		minutes, err := strconv.Atoi(strings.TrimSpace(ttl))
+		if err == nil && minutes > 0 {
+			return time.Duration(minutes) * time.Minute
+		}
+	}
+
+	return parseSyndication(feed)
+}
+
+// parseSyndication reads the RSS 1.0 syndication module (sy:updatePeriod, sy:updateFrequency)
+func parseSyndication(feed *gofeed.Feed) time.Duration {
+	sy, ok := feed.Extensions["sy"]
+	if !ok {
+		return 0
+	}
+
+	periods, ok := sy["updatePeriod"]
+	if !ok || len(periods) == 0 {
+		return 0
+	}
+
+	var period time.Duration
+	switch strings.ToLower(strings.TrimSpace(periods[0].Value)) {
+	case "hourly":
+		period = time.Hour
+	case "daily":
+		period = 24 * time.Hour
+	case "weekly":
+		period = 7 * 24 * time.Hour
+	case "monthly":
+		period = 30 * 24 * time.Hour
+	case "yearly":
+		period = 365 * 24 * time.Hour
+	default:
+		return 0
+	}
+
+	frequency := 1
+	if frequencies, ok := sy["updateFrequency"]; ok && len(frequencies) > 0 {
+		f, err := strconv.Atoi(strings.TrimSpace(frequencies[0].Value))
+		if err == nil && f > 0 {
+			frequency = f
+		}
+	}
+
+	return period / time.Duration(frequency)
+}
+
+func parseMaxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(directive), "=")
+		if !found || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+
+		seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+		if err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	return 0
+}
+
+// parseRetryAfter handles both forms of the header: delay in seconds or an HTTP date
+func parseRetryAfter(retryAfter string, now time.Time) time.Time {
+	retryAfter = strings.TrimSpace(retryAfter)
+	if retryAfter == "" {
+		return time.Time{}
+	}
+
+	seconds, err := strconv.Atoi(retryAfter)
+	if err == nil {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+
+	date, err := http.ParseTime(retryAfter)
+	if err == nil {
+		return date
+	}
+
+	return time.Time{}
+}
@@ -3,18 +3,104 @@ package requests
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/mmcdole/gofeed"
 )
 
-var fp = gofeed.NewParser()
+const userAgent = "gator/1.0"
 
-func FetchFeed(ctx context.Context, feedUrl string) (*gofeed.Feed, error) {
-	feed, err := fp.ParseURL(feedUrl)
+var fp = newParser()
 
+var client = &http.Client{}
+
+// Validators are the HTTP cache validators returned by a previous fetch of a feed.
+// Empty fields are simply not sent.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// FetchResult holds the parsed feed and the validators to send on the next fetch.
+// When NotModified is true the server answered 304 and Feed is nil.
+type FetchResult struct {
+	Feed        *gofeed.Feed
+	NotModified bool
+	Validators  Validators
+	Hints       Hints
+}
+
+// StatusError is returned when the server answers with a non 2xx (and non 304) status
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Hints      Hints
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("http error: %s", e.Status)
+}
+
+func newParser() *gofeed.Parser {
+	parser := gofeed.NewParser()
+	parser.RSSTranslator = &rssTranslator{}
+	return parser
+}
+
+func FetchFeed(ctx context.Context, feedUrl string, validators Validators) (*FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error doing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	hints := hintsFromResponse(resp, time.Now().UTC())
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &FetchResult{
+			NotModified: true,
+			Validators:  validators,
+			Hints:       hints,
+		}, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Hints:      hints,
+		}
+	}
+
+	feed, err := fp.Parse(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing url: %w", err)
+		return nil, fmt.Errorf("error parsing feed: %w", err)
 	}
 
-	return feed, nil
+	hints.TTL = ttlFromFeed(feed)
+
+	return &FetchResult{
+		Feed: feed,
+		Validators: Validators{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		},
+		Hints: hints,
+	}, nil
 }
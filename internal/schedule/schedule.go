@@ -0,0 +1,62 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/requests"
+)
+
+const (
+	// DefaultInterval is used for feeds we don't have enough posts for to guess a frequency
+	DefaultInterval = time.Hour
+	MaxInterval     = 24 * time.Hour
+
+	// HistorySize is how many of the most recent publish dates are used to estimate the frequency
+	HistorySize = 10
+
+	// DisableAfterFailures is how many fetches in a row can fail before a feed is disabled
+	DisableAfterFailures = 10
+)
+
+// NextFetch computes when a feed should be fetched again.
+// published are the most recent publish dates of the feed posts, newest first.
+// The interval is half of the average time between posts, so a feed posting hourly is checked
+// every 30 minutes and a feed posting monthly roughly every day, clamped between minInterval
+// and MaxInterval. The publisher's hints (ttl, max-age, Retry-After) can only push it further away.
+func NextFetch(now time.Time, published []time.Time, hints requests.Hints, minInterval time.Duration) time.Time {
+	interval := DefaultInterval
+
+	if len(published) >= 2 {
+		span := published[0].Sub(published[len(published)-1])
+		interval = span / time.Duration(len(published)-1) / 2
+	}
+
+	interval = min(interval, MaxInterval)
+	interval = max(interval, minInterval, hints.TTL, hints.MaxAge)
+
+	next := now.Add(interval)
+
+	if hints.RetryAfter.After(next) {
+		next = hints.RetryAfter
+	}
+
+	return next
+}
+
+// Backoff computes when a failing feed should be retried, doubling the wait after each
+// consecutive failure, up to MaxInterval. A Retry-After from the server is honored if later.
+func Backoff(now time.Time, failures int32, hints requests.Hints, minInterval time.Duration) time.Time {
+	interval := max(minInterval, time.Minute)
+
+	for i := int32(1); i < failures && interval < MaxInterval; i++ {
+		interval *= 2
+	}
+
+	next := now.Add(min(interval, MaxInterval))
+
+	if hints.RetryAfter.After(next) {
+		next = hints.RetryAfter
+	}
+
+	return next
+}
@@ -6,11 +6,14 @@ import (
 
 	"github.com/Ciobi0212/gator.git/internal/config"
 	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/output"
 )
 
 type AppState struct {
 	Cfg *config.Config
 	Db  *database.Queries
+	// Out prints the results of commands in the format asked with --output
+	Out *output.Printer
 }
 
 func GetInitState() (*AppState, error) {
@@ -31,6 +34,7 @@ func GetInitState() (*AppState, error) {
 	state := AppState{
 		Cfg: cfg,
 		Db:  dbQueries,
+		Out: output.NewPrinter(output.FormatText),
 	}
 
 	return &state, nil
@@ -0,0 +1,135 @@
+package textdiff
+
+import "strings"
+
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines computes a line diff turning a into b, based on their longest common subsequence
+func Lines(a, b []string) []Line {
+	// Edits are usually small, the unchanged head and tail are left out of the quadratic part
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(a)+len(b))
+	for _, text := range a[:prefix] {
+		lines = append(lines, Line{Equal, text})
+	}
+
+	lines = append(lines, lcsDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, Line{Equal, text})
+	}
+
+	return lines
+}
+
+func lcsDiff(a, b []string) []Line {
+	// lengths[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var lines []Line
+	i, j := 0, 0
+
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Equal, a[i]})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			lines = append(lines, Line{Delete, a[i]})
+			i++
+		default:
+			lines = append(lines, Line{Insert, b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		lines = append(lines, Line{Delete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, Line{Insert, b[j]})
+	}
+
+	return lines
+}
+
+// Format prints the diff with - and + markers, keeping only context unchanged lines
+// around each change. Skipped unchanged lines are replaced by "...".
+func Format(lines []Line, context int) string {
+	// keep[i] tells if line i is a change or close enough to one
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if line.Op == Equal {
+			continue
+		}
+		for k := max(i-context, 0); k <= min(i+context, len(lines)-1); k++ {
+			keep[k] = true
+		}
+	}
+
+	var sb strings.Builder
+	skipped := false
+
+	for i, line := range lines {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+
+		if skipped {
+			sb.WriteString("...\n")
+			skipped = false
+		}
+
+		switch line.Op {
+		case Insert:
+			sb.WriteString("+ ")
+		case Delete:
+			sb.WriteString("- ")
+		default:
+			sb.WriteString("  ")
+		}
+		sb.WriteString(line.Text)
+		sb.WriteString("\n")
+	}
+
+	if skipped {
+		sb.WriteString("...\n")
+	}
+
+	return sb.String()
+}
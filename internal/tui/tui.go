@@ -0,0 +1,263 @@
+package tui
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// refreshInterval is how often feeds and posts are reloaded, to show what agg fetched meanwhile
+	refreshInterval = 30 * time.Second
+	postsLimit      = 200
+)
+
+type pane int
+
+const (
+	paneFeeds pane = iota
+	panePosts
+	panePreview
+)
+
+type model struct {
+	ctx  context.Context
+	db   *database.Queries
+	user database.User
+
+	// feeds[0] is the "All feeds" entry, the others are the feeds the user follows
+	feeds []database.GetFeedFollowsForUserRow
+	posts []database.GetPostsForUserRow
+
+	focus         pane
+	feedCursor    int
+	postCursor    int
+	previewScroll int
+	unreadOnly    bool
+
+	width  int
+	height int
+	status string
+}
+
+type feedsLoadedMsg []database.GetFeedFollowsForUserRow
+
+// postsLoadedMsg is tagged with what was asked, a reply arriving after another feed was selected is dropped
+type postsLoadedMsg struct {
+	feedID     int32
+	unreadOnly bool
+	posts      []database.GetPostsForUserRow
+}
+
+type postReadMsg int32
+
+type refreshMsg time.Time
+
+type errMsg struct{ err error }
+
+// Run starts the full screen reader for user, until they quit or ctx is cancelled
+func Run(ctx context.Context, db *database.Queries, user database.User) error {
+	m := &model{
+		ctx:   ctx,
+		db:    db,
+		user:  user,
+		feeds: []database.GetFeedFollowsForUserRow{{Name: "All feeds"}},
+	}
+
+	_, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	if err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+		return fmt.Errorf("err running tui: %w", err)
+	}
+
+	return nil
+}
+
+func (m *model) Init() tea.Cmd {
+	return tea.Batch(m.loadFeeds(), m.loadPosts(), scheduleRefresh())
+}
+
+func scheduleRefresh() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
+		return refreshMsg(t)
+	})
+}
+
+func (m *model) loadFeeds() tea.Cmd {
+	return func() tea.Msg {
+		follows, err := m.db.GetFeedFollowsForUser(m.ctx, m.user.ID)
+		if err != nil {
+			return errMsg{fmt.Errorf("err getting feeds for user: %w", err)}
+		}
+		return feedsLoadedMsg(follows)
+	}
+}
+
+func (m *model) loadPosts() tea.Cmd {
+	arg := database.GetPostsForUserParams{
+		UserID:     m.user.ID,
+		UnreadOnly: m.unreadOnly,
+		Limit:      postsLimit,
+	}
+
+	if m.feedCursor > 0 {
+		arg.FeedUrl = sql.NullString{String: m.feeds[m.feedCursor].Url, Valid: true}
+	}
+
+	// "All feeds" has the id 0
+	feedID := m.feeds[m.feedCursor].ID
+
+	return func() tea.Msg {
+		posts, err := m.db.GetPostsForUser(m.ctx, arg)
+		if err != nil {
+			return errMsg{fmt.Errorf("err getting posts for user: %w", err)}
+		}
+		return postsLoadedMsg{feedID: feedID, unreadOnly: arg.UnreadOnly, posts: posts}
+	}
+}
+
+func (m *model) markRead(post database.GetPostsForUserRow) tea.Cmd {
+	return func() tea.Msg {
+		err := m.db.MarkPostRead(
+			m.ctx,
+			database.MarkPostReadParams{
+				UserID: m.user.ID,
+				PostID: post.ID,
+				ReadAt: time.Now().UTC(),
+			},
+		)
+		if err != nil {
+			return errMsg{fmt.Errorf("err marking post read: %w", err)}
+		}
+		return postReadMsg(post.ID)
+	}
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+	case feedsLoadedMsg:
+		m.feeds = append(m.feeds[:1], msg...)
+		m.feedCursor = min(m.feedCursor, len(m.feeds)-1)
+
+	case postsLoadedMsg:
+		if msg.feedID != m.feeds[m.feedCursor].ID || msg.unreadOnly != m.unreadOnly {
+			return m, nil
+		}
+		m.posts = msg.posts
+		m.postCursor = min(m.postCursor, max(len(m.posts)-1, 0))
+
+	case postReadMsg:
+		for i := range m.posts {
+			if m.posts[i].ID == int32(msg) {
+				m.posts[i].IsRead = true
+			}
+		}
+		return m, m.loadFeeds()
+
+	case refreshMsg:
+		return m, tea.Batch(m.loadFeeds(), m.loadPosts(), scheduleRefresh())
+
+	case errMsg:
+		m.status = msg.err.Error()
+
+	case tea.KeyMsg:
+		return m, m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m *model) handleKey(msg tea.KeyMsg) tea.Cmd {
+	m.status = ""
+
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return tea.Quit
+
+	case "tab", "l", "right":
+		m.focus = min(m.focus+1, panePreview)
+	case "shift+tab", "h", "left":
+		m.focus = max(m.focus-1, paneFeeds)
+
+	case "j", "down":
+		return m.move(1)
+	case "k", "up":
+		return m.move(-1)
+
+	case "enter":
+		if m.focus == paneFeeds {
+			m.focus = panePosts
+			return nil
+		}
+		if m.focus == panePosts && len(m.posts) > 0 {
+			m.focus = panePreview
+			return m.markRead(m.posts[m.postCursor])
+		}
+
+	case "m":
+		if len(m.posts) > 0 {
+			return m.markRead(m.posts[m.postCursor])
+		}
+
+	case "o":
+		if len(m.posts) > 0 {
+			post := m.posts[m.postCursor]
+			err := openURL(post.Url)
+			if err != nil {
+				m.status = fmt.Sprintf("can't open %s: %v", post.Url, err)
+				return nil
+			}
+			return m.markRead(post)
+		}
+
+	case "u":
+		m.unreadOnly = !m.unreadOnly
+		m.postCursor = 0
+		return m.loadPosts()
+
+	case "r":
+		return tea.Batch(m.loadFeeds(), m.loadPosts())
+	}
+
+	return nil
+}
+
+func (m *model) move(delta int) tea.Cmd {
+	switch m.focus {
+	case paneFeeds:
+		cursor := clamp(m.feedCursor+delta, 0, len(m.feeds)-1)
+		if cursor == m.feedCursor {
+			return nil
+		}
+		m.feedCursor = cursor
+		m.postCursor = 0
+		m.previewScroll = 0
+		return m.loadPosts()
+
+	case panePosts:
+		m.postCursor = clamp(m.postCursor+delta, 0, len(m.posts)-1)
+		m.previewScroll = 0
+
+	case panePreview:
+		if len(m.posts) == 0 {
+			return nil
+		}
+		height, _, _, previewWidth := m.layout()
+		lines := m.previewLines(m.posts[m.postCursor], previewWidth)
+		m.previewScroll = clamp(m.previewScroll+delta, 0, max(len(lines)-height, 0))
+	}
+
+	return nil
+}
+
+func clamp(value, low, high int) int {
+	return max(low, min(value, high))
+}
@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Ciobi0212/gator.git/internal/database"
+	"github.com/Ciobi0212/gator.git/internal/render"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	paneStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("240"))
+
+	focusedPaneStyle = paneStyle.BorderForeground(lipgloss.Color("42"))
+
+	selectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("42"))
+	readStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	titleStyle    = lipgloss.NewStyle().Bold(true)
+	statusStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+)
+
+const helpLine = "tab/h/l: switch pane  j/k: move  enter: open  m: mark read  o: open link  u: unread only  r: refresh  q: quit"
+
+func (m *model) View() string {
+	if m.width == 0 {
+		return "Loading..."
+	}
+
+	height, feedsWidth, postsWidth, previewWidth := m.layout()
+
+	feeds := m.pane(paneFeeds).Width(feedsWidth).Height(height).Render(m.viewFeeds(feedsWidth, height))
+	posts := m.pane(panePosts).Width(postsWidth).Height(height).Render(m.viewPosts(postsWidth, height))
+	preview := m.pane(panePreview).Width(previewWidth).Height(height).Render(m.viewPreview(previewWidth, height))
+
+	status := m.status
+	if status == "" {
+		status = helpLine
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		lipgloss.JoinHorizontal(lipgloss.Top, feeds, posts, preview),
+		statusStyle.MaxWidth(m.width).Render(status),
+	)
+}
+
+// layout is the size of the content of each pane for the window size
+func (m *model) layout() (height, feedsWidth, postsWidth, previewWidth int) {
+	// Borders take 2 columns and 2 rows per pane, the status line takes 1 row
+	height = max(m.height-3, 1)
+	feedsWidth = max(m.width/4-2, 10)
+	postsWidth = max(m.width*3/8-2, 10)
+	previewWidth = max(m.width-feedsWidth-postsWidth-6, 10)
+	return height, feedsWidth, postsWidth, previewWidth
+}
+
+func (m *model) pane(p pane) lipgloss.Style {
+	if m.focus == p {
+		return focusedPaneStyle
+	}
+	return paneStyle
+}
+
+func (m *model) viewFeeds(width, height int) string {
+	lines := make([]string, 0, len(m.feeds))
+
+	for i, feed := range m.feeds {
+		line := feed.Name
+		if i > 0 && feed.UnreadCount > 0 {
+			line = fmt.Sprintf("%s (%d)", feed.Name, feed.UnreadCount)
+		}
+
+		lines = append(lines, m.listLine(line, i == m.feedCursor, false, width))
+	}
+
+	return strings.Join(visibleWindow(lines, m.feedCursor, height), "\n")
+}
+
+func (m *model) viewPosts(width, height int) string {
+	if len(m.posts) == 0 {
+		return readStyle.Render("No posts")
+	}
+
+	lines := make([]string, 0, len(m.posts))
+
+	for i, post := range m.posts {
+		marker := "● "
+		if post.IsRead {
+			marker = "  "
+		}
+
+		lines = append(lines, m.listLine(marker+post.Title, i == m.postCursor, post.IsRead, width))
+	}
+
+	return strings.Join(visibleWindow(lines, m.postCursor, height), "\n")
+}
+
+func (m *model) listLine(text string, selected bool, read bool, width int) string {
+	style := lipgloss.NewStyle()
+
+	if read {
+		style = readStyle
+	}
+	if selected {
+		style = selectedStyle
+	}
+
+	return style.MaxWidth(width).Render(text)
+}
+
+func (m *model) viewPreview(width, height int) string {
+	if len(m.posts) == 0 {
+		return ""
+	}
+
+	lines := m.previewLines(m.posts[m.postCursor], width)
+
+	// The scroll is clamped when moving, this only covers the window being resized since
+	scroll := min(m.previewScroll, max(len(lines)-height, 0))
+	end := min(scroll+height, len(lines))
+
+	return strings.Join(lines[scroll:end], "\n")
+}
+
+// previewLines is the whole preview of a post, wrapped to width
+func (m *model) previewLines(post database.GetPostsForUserRow, width int) []string {
+	header := []string{
+		titleStyle.Render(post.Title),
+		readStyle.Render(post.FeedName + " · " + post.PublishedAt.Format(time.DateTime)),
+		readStyle.Render(post.Url),
+		"",
+	}
+
+	content := post.Content
+	if strings.TrimSpace(content) == "" {
+		content = post.Description
+	}
+
+	body := render.HTMLToText(content, width)
+
+	lines := strings.Split(lipgloss.NewStyle().Width(width).Render(strings.Join(header, "\n")), "\n")
+	return append(lines, strings.Split(body, "\n")...)
+}
+
+// visibleWindow returns the lines that fit in height, keeping the cursor in view
+func visibleWindow(lines []string, cursor int, height int) []string {
+	if len(lines) <= height {
+		return lines
+	}
+
+	start := clamp(cursor-height/2, 0, len(lines)-height)
+
+	return lines[start : start+height]
+}
+
+// openURL opens a post link in the browser. Links come from the feeds, only web links are opened
+// so a link can't be an option of the opener or a local file or program.
+func openURL(link string) error {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("not a web link")
+	}
+
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u.String())
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u.String())
+	default:
+		cmd = exec.Command("xdg-open", u.String())
+	}
+
+	return cmd.Start()
+}
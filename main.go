@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/Ciobi0212/gator.git/internal/commands"
+	"github.com/Ciobi0212/gator.git/internal/output"
 	"github.com/Ciobi0212/gator.git/internal/state"
 	_ "github.com/lib/pq"
 )
@@ -20,7 +24,31 @@ func main() {
 		return
 	}
 
-	args := os.Args
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// First SIGINT/SIGTERM asks the running command to stop cleanly, the second one exits right away
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-signals
+		fmt.Println("\nStopping, press Ctrl-C again to force exit")
+		cancel()
+
+		<-signals
+		fmt.Println("Forced exit")
+		os.Exit(1)
+	}()
+
+	// --output can be given anywhere, it applies to every command
+	format, args, err := output.ExtractFlag(os.Args)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	state.Out = output.NewPrinter(format)
 
 	if len(args) < 2 {
 		// Show help information instead of error when no arguments are provided
@@ -28,9 +56,9 @@ func main() {
 			Name:   "help",
 			Params: []string{},
 		}
-		err = helpCommand.Run(state)
+		err = helpCommand.Run(ctx, state)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(state.Out.Messages, err)
 		}
 		return
 	}
@@ -42,15 +70,16 @@ func main() {
 		Params: args[2:],
 	}
 
-	err = command.Run(state)
+	err = command.Run(ctx, state)
 
 	var userErr *commands.UserFacingError
 
+	// Errors are messages, with a machine readable output they go to stderr
 	if err != nil {
 		if errors.As(err, &userErr) {
-			fmt.Println(userErr)
+			fmt.Fprintln(state.Out.Messages, userErr)
 		} else {
-			fmt.Println("Internal error, something went wrong")
+			fmt.Fprintln(state.Out.Messages, "Internal error, something went wrong")
 		}
 		os.Exit(1)
 	}
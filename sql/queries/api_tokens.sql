@@ -0,0 +1,18 @@
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (created_at, name, token_hash, user_id, scope)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetAPITokensForUser :many
+SELECT * FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: DeleteAPIToken :execrows
+DELETE FROM api_tokens
+WHERE id = $1 AND user_id = $2;
+
+-- name: FindUserByAPIToken :one
+SELECT users.* FROM users
+JOIN api_tokens ON api_tokens.user_id = users.id
+WHERE api_tokens.token_hash = $1 AND api_tokens.scope = $2;
@@ -24,7 +24,53 @@ SET last_fetched_at = NOW(), updated_at = NOW()
 WHERE id = $1;
 
 
--- name: GetNextFeedsToFetch :many
-SELECT * from feed 
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT $1;
\ No newline at end of file
+-- name: ClaimNextFeedToFetch :one
+UPDATE feed
+SET next_fetch_at = sqlc.arg(lease_until)::timestamp
+WHERE id = (
+    SELECT id from feed
+    WHERE disabled_at IS NULL
+    AND (next_fetch_at IS NULL OR next_fetch_at <= sqlc.arg(now)::timestamp)
+    ORDER BY next_fetch_at ASC NULLS FIRST, last_fetched_at ASC NULLS FIRST
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: SetFeedValidators :exec
+UPDATE feed
+SET etag = $2, last_modified = $3, updated_at = NOW()
+WHERE id = $1;
+
+
+-- name: SetFeedNextFetchAt :exec
+UPDATE feed
+SET next_fetch_at = $2, updated_at = NOW()
+WHERE id = $1;
+
+
+-- name: RecordFeedSuccess :exec
+UPDATE feed
+SET last_error = NULL, consecutive_failures = 0, last_success_at = NOW(), updated_at = NOW()
+WHERE id = $1;
+
+-- name: RecordFeedFailure :one
+UPDATE feed
+SET last_error = $2, consecutive_failures = consecutive_failures + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING consecutive_failures;
+
+-- name: DisableFeed :exec
+UPDATE feed
+SET disabled_at = NOW(), updated_at = NOW()
+WHERE id = $1;
+
+-- name: EnableFeed :exec
+UPDATE feed
+SET disabled_at = NULL, consecutive_failures = 0, next_fetch_at = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetBrokenFeeds :many
+SELECT * FROM feed
+WHERE consecutive_failures > 0 OR disabled_at IS NOT NULL
+ORDER BY consecutive_failures DESC;
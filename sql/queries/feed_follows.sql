@@ -1,11 +1,11 @@
 -- name: CreateFeedFollow :one
 WITH inserted_feed_follows AS (
-    INSERT INTO feed_follows(created_at, updated_at, user_id, feed_id)
-    VALUES ($1, $2, $3, $4)
+    INSERT INTO feed_follows(created_at, updated_at, user_id, feed_id, folder_id, title)
+    VALUES ($1, $2, $3, $4, $5, $6)
     RETURNING *
 )
 
-SELECT inserted_feed_follows.*, users.name, feed.name 
+SELECT inserted_feed_follows.*, users.name, COALESCE(inserted_feed_follows.title, feed.name)::varchar AS feed_name
 FROM inserted_feed_follows 
 JOIN users on users.id = inserted_feed_follows.user_id
 JOIN feed on feed.id = inserted_feed_follows.feed_id;
@@ -14,11 +14,28 @@ JOIN feed on feed.id = inserted_feed_follows.feed_id;
 
 WITH feed_follows_entries AS (
     SELECT * from feed_follows
-    WHERE user_id = $1
+    WHERE feed_follows.user_id = $1
 )
 
-SELECT feed.name, feed.url FROM feed_follows_entries
-JOIN feed ON feed.id = feed_follows_entries.feed_id;
+SELECT feed.id, COALESCE(feed_follows_entries.title, feed.name)::varchar AS name, feed.url, folders.name AS folder,
+    (
+        SELECT COUNT(*) FROM posts
+        WHERE posts.feed_id = feed.id
+        AND NOT EXISTS (
+            SELECT 1 FROM post_reads
+            WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows_entries.user_id
+        )
+        AND post_passes_filters(feed_follows_entries.user_id, posts.feed_id, posts.title, posts.description, posts.content, posts.author, posts.categories)
+    ) AS unread_count
+FROM feed_follows_entries
+JOIN feed ON feed.id = feed_follows_entries.feed_id
+LEFT JOIN folders ON folders.id = feed_follows_entries.folder_id
+ORDER BY folders.name NULLS FIRST, COALESCE(feed_follows_entries.title, feed.name);
+
+-- name: SetFeedFollowTitle :execrows
+UPDATE feed_follows
+SET title = $3, updated_at = $4
+WHERE user_id = $1 AND feed_id = $2;
 
 -- name: DeleteFeedFollowsEntry :exec
 DELETE FROM feed_follows
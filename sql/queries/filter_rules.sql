@@ -0,0 +1,19 @@
+-- name: CreateFilterRule :one
+INSERT INTO filter_rules (created_at, user_id, feed_id, action, field, match_type, pattern)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetFilterRulesForUser :many
+SELECT filter_rules.*, feed.url AS feed_url
+FROM filter_rules
+LEFT JOIN feed ON feed.id = filter_rules.feed_id
+WHERE filter_rules.user_id = $1
+ORDER BY filter_rules.id;
+
+-- name: DeleteFilterRule :execrows
+DELETE FROM filter_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: CheckRegex :exec
+-- Fails when pattern is not a valid regex for postgres, before it is stored and breaks the post queries
+SELECT '' ~* sqlc.arg(pattern)::varchar;
@@ -0,0 +1,29 @@
+-- name: CreateFolder :one
+INSERT INTO folders (created_at, user_id, name)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: EnsureFolder :one
+-- Returns the folder of the user with this name, creating it if needed
+INSERT INTO folders (created_at, user_id, name)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: FindFolderByName :one
+SELECT * FROM folders
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetFoldersForUser :many
+SELECT * FROM folders
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: DeleteFolder :execrows
+DELETE FROM folders
+WHERE user_id = $1 AND name = $2;
+
+-- name: SetFeedFollowFolder :execrows
+UPDATE feed_follows
+SET folder_id = $3, updated_at = $4
+WHERE user_id = $1 AND feed_id = $2;
@@ -0,0 +1,12 @@
+-- name: CreatePostEnclosure :exec
+INSERT INTO post_enclosures (post_id, url, type, length)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetPostEnclosures :many
+SELECT * FROM post_enclosures
+WHERE post_id = $1
+ORDER BY id;
+
+-- name: DeletePostEnclosures :exec
+DELETE FROM post_enclosures
+WHERE post_id = $1;
@@ -0,0 +1,4 @@
+-- name: GetPostRevisions :many
+SELECT * FROM post_revisions
+WHERE post_id = $1
+ORDER BY replaced_at, id;
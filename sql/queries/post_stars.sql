@@ -0,0 +1,19 @@
+-- name: StarPost :exec
+INSERT INTO post_stars (user_id, post_id, starred_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: UnstarPost :execrows
+DELETE FROM post_stars
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetStarredPostsForUser :many
+-- Starred posts stay in the list after their feed is unfollowed
+SELECT posts.id, posts.title, posts.url, posts.description, posts.author, posts.published_at,
+    COALESCE(feed_follows.title, feed.name)::varchar AS feed_name, post_stars.starred_at
+FROM post_stars
+JOIN posts ON posts.id = post_stars.post_id
+JOIN feed ON feed.id = posts.feed_id
+LEFT JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = post_stars.user_id
+WHERE post_stars.user_id = $1
+ORDER BY post_stars.starred_at DESC;
@@ -1,25 +1,145 @@
--- name: CreatePost :one
-INSERT INTO posts (created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES (
-    $1,
-    $2,
-    $3,
-    $4,
-    $5,
-    $6,
-    $7
+-- name: UpsertPost :one
+-- The version being replaced is kept in post_revisions, unless it predates content hashes.
+-- Such a post only gets its hash: it's not revised, it was saved before gator could tell.
+-- Returns no row when the post didn't change or was pruned.
+WITH previous AS (
+    SELECT id, updated_at, title, url, description, content, author, categories, image_url, content_hash
+    FROM posts
+    WHERE feed_id = sqlc.arg(feed_id) AND dedup_key = sqlc.arg(dedup_key)
+), revision AS (
+    INSERT INTO post_revisions (post_id, created_at, replaced_at, title, url, description, content, author, categories, image_url, content_hash)
+    SELECT id, updated_at, sqlc.arg(updated_at)::timestamp, title, url, description, content, author, categories, image_url, content_hash
+    FROM previous
+    WHERE previous.content_hash <> '' AND previous.content_hash <> sqlc.arg(content_hash)
 )
-ON CONFLICT (url) DO NOTHING
-RETURNING *;
+INSERT INTO posts (created_at, updated_at, title, url, description, published_at, feed_id, content, author, categories, guid, image_url, dedup_key, content_hash)
+SELECT
+    sqlc.arg(created_at)::timestamp,
+    sqlc.arg(updated_at)::timestamp,
+    sqlc.arg(title)::varchar,
+    sqlc.arg(url)::varchar,
+    sqlc.arg(description)::varchar,
+    sqlc.arg(published_at)::timestamp,
+    sqlc.arg(feed_id)::integer,
+    sqlc.arg(content)::varchar,
+    sqlc.arg(author)::varchar,
+    sqlc.arg(categories)::text[],
+    sqlc.arg(guid)::varchar,
+    sqlc.arg(image_url)::varchar,
+    sqlc.arg(dedup_key)::varchar,
+    sqlc.arg(content_hash)::varchar
+-- Pruned posts still in the feed stay pruned
+WHERE NOT EXISTS (
+    SELECT 1 FROM pruned_posts
+    WHERE pruned_posts.feed_id = sqlc.arg(feed_id) AND pruned_posts.dedup_key = sqlc.arg(dedup_key)
+)
+ON CONFLICT (feed_id, dedup_key) DO UPDATE SET
+    updated_at = EXCLUDED.updated_at,
+    title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    content = EXCLUDED.content,
+    author = EXCLUDED.author,
+    categories = EXCLUDED.categories,
+    image_url = EXCLUDED.image_url,
+    content_hash = EXCLUDED.content_hash
+WHERE posts.content_hash <> EXCLUDED.content_hash
+RETURNING id, (xmax = 0)::boolean AS inserted,
+    EXISTS (SELECT 1 FROM previous WHERE previous.content_hash <> '') AS revised;
 
 -- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, COALESCE(feed_follows.title, feed.name)::varchar AS feed_name, posts.content,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+    ) AS is_read
 FROM posts
 JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
-WHERE feed_follows.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2;
+JOIN feed ON feed.id = posts.feed_id
+LEFT JOIN folders ON folders.id = feed_follows.folder_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (
+    NOT sqlc.arg(unread_only)::boolean
+    OR NOT EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+    )
+)
+AND (sqlc.narg(feed_url)::varchar IS NULL OR feed.url = sqlc.narg(feed_url))
+-- A folder includes its subfolders
+AND (
+    sqlc.narg(folder)::varchar IS NULL
+    OR folders.name = sqlc.narg(folder)
+    OR starts_with(folders.name, sqlc.narg(folder) || '/')
+)
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR posts.published_at < sqlc.narg(until))
+AND (sqlc.narg(author)::varchar IS NULL OR strpos(lower(posts.author), lower(sqlc.narg(author))) > 0)
+AND post_passes_filters(feed_follows.user_id, posts.feed_id, posts.title, posts.description, posts.content, posts.author, posts.categories)
+ORDER BY
+    CASE WHEN sqlc.arg(oldest_first)::boolean THEN posts.published_at END ASC,
+    CASE WHEN NOT sqlc.arg(oldest_first)::boolean THEN posts.published_at END DESC,
+    posts.id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: FindPostForUser :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id,
+    posts.content, posts.author, posts.categories, posts.guid, posts.image_url, COALESCE(feed_follows.title, feed.name)::varchar AS feed_name
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feed ON feed.id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.id = $2;
   
 
 -- name: DeleteAllPosts :exec
-DELETE FROM posts;
\ No newline at end of file
+DELETE FROM posts;
+
+-- name: GetRecentPublishedDatesForFeed :many
+SELECT published_at FROM posts
+WHERE feed_id = $1 AND published_at > '0001-01-01'
+ORDER BY published_at DESC
+LIMIT $2;
+
+-- name: GetLegacyKeyPosts :many
+-- Posts saved before deduplication, still keyed by a placeholder (see 014_posts_dedup_key.sql)
+SELECT id, feed_id, guid, url FROM posts
+WHERE dedup_key LIKE 'legacy:%'
+ORDER BY id
+LIMIT $1;
+
+-- name: SetPostDedupKey :execrows
+-- Leaves the post alone when another post of its feed already has the key
+UPDATE posts SET dedup_key = sqlc.arg(dedup_key)
+WHERE posts.id = sqlc.arg(id)
+AND NOT EXISTS (
+    SELECT 1 FROM posts AS other
+    WHERE other.feed_id = posts.feed_id AND other.dedup_key = sqlc.arg(dedup_key) AND other.id <> posts.id
+);
+
+-- name: MergeLegacyPost :execrows
+-- Removes a post still keyed by a placeholder when another post of its feed has its real key,
+-- the stars and reads of the removed post move to the other one. A post rekeyed in the
+-- meantime (by another agg or prune) is left alone.
+WITH duplicate AS (
+    SELECT posts.id, survivor.id AS survivor_id
+    FROM posts
+    JOIN posts AS survivor
+        ON survivor.feed_id = posts.feed_id AND survivor.dedup_key = sqlc.arg(dedup_key) AND survivor.id <> posts.id
+    WHERE posts.id = sqlc.arg(id) AND posts.dedup_key LIKE 'legacy:%'
+), moved_stars AS (
+    INSERT INTO post_stars (user_id, post_id, starred_at)
+    SELECT post_stars.user_id, duplicate.survivor_id, post_stars.starred_at
+    FROM post_stars
+    JOIN duplicate ON duplicate.id = post_stars.post_id
+    ON CONFLICT (user_id, post_id) DO NOTHING
+), moved_reads AS (
+    INSERT INTO post_reads (user_id, post_id, read_at)
+    SELECT post_reads.user_id, duplicate.survivor_id, post_reads.read_at
+    FROM post_reads
+    JOIN duplicate ON duplicate.id = post_reads.post_id
+    ON CONFLICT (user_id, post_id) DO NOTHING
+)
+DELETE FROM posts
+USING duplicate
+WHERE posts.id = duplicate.id;
@@ -0,0 +1,55 @@
+-- name: PrunePosts :many
+-- Removes the posts older than older_than, or beyond the max_posts most recent of their feed
+-- (0 for no limit). Starred posts are always kept, unread ones if keep_unread. With dry_run
+-- nothing is removed. Returns the number of posts removed per feed.
+WITH ranked AS (
+    SELECT posts.id, posts.feed_id, posts.dedup_key, posts.published_at, posts.created_at,
+        ROW_NUMBER() OVER (PARTITION BY posts.feed_id ORDER BY posts.published_at DESC, posts.id DESC) AS position
+    FROM posts
+), doomed AS (
+    SELECT ranked.id, ranked.feed_id, ranked.dedup_key
+    FROM ranked
+    WHERE (
+        -- Posts without a publish date are aged from when gator saved them
+        (
+            sqlc.narg(older_than)::timestamp IS NOT NULL
+            AND CASE WHEN ranked.published_at > '0001-01-01' THEN ranked.published_at ELSE ranked.created_at END < sqlc.narg(older_than)
+        )
+        OR (sqlc.arg(max_posts)::integer > 0 AND ranked.position > sqlc.arg(max_posts)::integer)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM post_stars
+        WHERE post_stars.post_id = ranked.id
+    )
+    AND (
+        NOT sqlc.arg(keep_unread)::boolean
+        OR NOT EXISTS (
+            SELECT 1 FROM feed_follows
+            WHERE feed_follows.feed_id = ranked.feed_id
+            AND NOT EXISTS (
+                SELECT 1 FROM post_reads
+                WHERE post_reads.post_id = ranked.id AND post_reads.user_id = feed_follows.user_id
+            )
+        )
+    )
+), deleted AS (
+    DELETE FROM posts
+    USING doomed
+    WHERE posts.id = doomed.id AND NOT sqlc.arg(dry_run)::boolean
+    RETURNING posts.feed_id, posts.dedup_key
+), tombstones AS (
+    INSERT INTO pruned_posts (feed_id, dedup_key, pruned_at)
+    SELECT deleted.feed_id, deleted.dedup_key, sqlc.arg(now)::timestamp
+    FROM deleted
+    ON CONFLICT (feed_id, dedup_key) DO NOTHING
+)
+SELECT feed.id, feed.name, COUNT(*) AS removed
+FROM doomed
+JOIN feed ON feed.id = doomed.feed_id
+GROUP BY feed.id, feed.name
+ORDER BY removed DESC, feed.name;
+
+-- name: ForgetPrunedPosts :exec
+-- A pruned post no longer listed by its feed can't be inserted again, so it doesn't need a tombstone
+DELETE FROM pruned_posts
+WHERE feed_id = sqlc.arg(feed_id) AND NOT (dedup_key = ANY(sqlc.arg(keys)::varchar[]));
@@ -0,0 +1,14 @@
+-- name: SearchPostsForUser :many
+SELECT posts.id, posts.title, posts.url, posts.published_at, COALESCE(feed_follows.title, feed.name)::varchar AS feed_name,
+    ts_rank(posts.search_vector, websearch_to_tsquery('english', sqlc.arg(query))) AS rank
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feed ON feed.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND posts.search_vector @@ websearch_to_tsquery('english', sqlc.arg(query))
+AND (sqlc.narg(feed_url)::varchar IS NULL OR feed.url = sqlc.narg(feed_url))
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR posts.published_at < sqlc.narg(until))
+AND post_passes_filters(feed_follows.user_id, posts.feed_id, posts.title, posts.description, posts.content, posts.author, posts.categories)
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT sqlc.arg(max_results);
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE feed
+ADD COLUMN etag VARCHAR;
+
+ALTER TABLE feed
+ADD COLUMN last_modified VARCHAR;
+
+-- +goose Down
+ALTER TABLE feed
+DROP COLUMN last_modified;
+
+ALTER TABLE feed
+DROP COLUMN etag;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feed
+ADD COLUMN next_fetch_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE feed
+DROP COLUMN next_fetch_at;
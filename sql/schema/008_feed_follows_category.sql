@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feed_follows
+ADD COLUMN category VARCHAR;
+
+-- +goose Down
+ALTER TABLE feed_follows
+DROP COLUMN category;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE api_tokens (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    name VARCHAR NOT NULL,
+    token_hash VARCHAR NOT NULL UNIQUE,
+    user_id uuid NOT NULL,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE api_tokens;
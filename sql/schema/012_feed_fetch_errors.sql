@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE feed
+ADD COLUMN last_error VARCHAR;
+
+ALTER TABLE feed
+ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE feed
+ADD COLUMN last_success_at TIMESTAMP;
+
+ALTER TABLE feed
+ADD COLUMN disabled_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE feed
+DROP COLUMN disabled_at;
+
+ALTER TABLE feed
+DROP COLUMN last_success_at;
+
+ALTER TABLE feed
+DROP COLUMN consecutive_failures;
+
+ALTER TABLE feed
+DROP COLUMN last_error;
@@ -0,0 +1,44 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN content VARCHAR NOT NULL DEFAULT '';
+
+ALTER TABLE posts
+ADD COLUMN author VARCHAR NOT NULL DEFAULT '';
+
+ALTER TABLE posts
+ADD COLUMN categories TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE posts
+ADD COLUMN guid VARCHAR NOT NULL DEFAULT '';
+
+ALTER TABLE posts
+ADD COLUMN image_url VARCHAR NOT NULL DEFAULT '';
+
+CREATE TABLE post_enclosures (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER NOT NULL,
+    url VARCHAR NOT NULL,
+    type VARCHAR NOT NULL,
+    length BIGINT NOT NULL,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE post_enclosures;
+
+ALTER TABLE posts
+DROP COLUMN image_url;
+
+ALTER TABLE posts
+DROP COLUMN guid;
+
+ALTER TABLE posts
+DROP COLUMN categories;
+
+ALTER TABLE posts
+DROP COLUMN author;
+
+ALTER TABLE posts
+DROP COLUMN content;
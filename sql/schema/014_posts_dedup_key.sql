@@ -0,0 +1,37 @@
+-- +goose Up
+-- Posts are now unique per feed on their guid, or their normalized url when they have none.
+-- The key is computed in Go (dedup.Key) and url normalization can't be reproduced in SQL, so existing
+-- posts get a placeholder unique to each post. gator replaces it with the real key, and removes the
+-- duplicates it reveals, before fetching or pruning anything (see rekeyLegacyPosts).
+ALTER TABLE posts
+DROP CONSTRAINT posts_url_key;
+
+ALTER TABLE posts
+ADD COLUMN dedup_key VARCHAR;
+
+UPDATE posts
+SET dedup_key = 'legacy:' || id;
+
+ALTER TABLE posts
+ALTER COLUMN dedup_key SET NOT NULL;
+
+CREATE UNIQUE INDEX posts_feed_id_dedup_key_idx ON posts (feed_id, dedup_key);
+
+-- Finds the posts left to rekey without scanning the table, it's empty once they are done
+CREATE INDEX posts_legacy_dedup_key_idx ON posts (id) WHERE dedup_key LIKE 'legacy:%';
+
+-- +goose Down
+DROP INDEX posts_legacy_dedup_key_idx;
+
+DROP INDEX posts_feed_id_dedup_key_idx;
+
+ALTER TABLE posts
+DROP COLUMN dedup_key;
+
+DELETE FROM posts AS duplicate
+USING posts AS original
+WHERE duplicate.url = original.url
+AND duplicate.id > original.id;
+
+ALTER TABLE posts
+ADD CONSTRAINT posts_url_key UNIQUE (url);
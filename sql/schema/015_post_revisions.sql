@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN content_hash VARCHAR NOT NULL DEFAULT '';
+
+CREATE TABLE post_revisions (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    replaced_at TIMESTAMP NOT NULL,
+    title VARCHAR NOT NULL,
+    url VARCHAR NOT NULL,
+    description VARCHAR NOT NULL,
+    content VARCHAR NOT NULL,
+    content_hash VARCHAR NOT NULL,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX post_revisions_post_id_idx ON post_revisions (post_id);
+
+-- +goose Down
+DROP TABLE post_revisions;
+
+ALTER TABLE posts
+DROP COLUMN content_hash;
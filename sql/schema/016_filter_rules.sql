@@ -0,0 +1,102 @@
+-- +goose Up
+CREATE TABLE filter_rules (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id uuid NOT NULL,
+    -- NULL applies the rule to every feed the user follows
+    feed_id INTEGER,
+    action VARCHAR NOT NULL CHECK (action IN ('include', 'exclude')),
+    field VARCHAR NOT NULL CHECK (field IN ('any', 'title', 'description', 'author', 'category')),
+    match_type VARCHAR NOT NULL CHECK (match_type IN ('keyword', 'regex')),
+    pattern VARCHAR NOT NULL,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    FOREIGN KEY (feed_id)
+    REFERENCES feed(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX filter_rules_user_id_idx ON filter_rules (user_id);
+
+-- Keywords match case-insensitively anywhere in the text, regexes are case-insensitive POSIX regexes
+-- +goose StatementBegin
+CREATE FUNCTION filter_text_matches(match_type VARCHAR, pattern VARCHAR, value VARCHAR) RETURNS BOOLEAN
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT CASE
+        WHEN match_type = 'regex' THEN value ~* pattern
+        ELSE strpos(lower(value), lower(pattern)) > 0
+    END
+$$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION filter_rule_matches(
+    field VARCHAR, match_type VARCHAR, pattern VARCHAR,
+    title VARCHAR, description VARCHAR, content VARCHAR, author VARCHAR, categories TEXT[]
+) RETURNS BOOLEAN
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT CASE field
+        WHEN 'title' THEN filter_text_matches(match_type, pattern, title)
+        WHEN 'description' THEN filter_text_matches(match_type, pattern, description)
+            OR filter_text_matches(match_type, pattern, content)
+        WHEN 'author' THEN filter_text_matches(match_type, pattern, author)
+        WHEN 'category' THEN EXISTS (
+            SELECT 1 FROM unnest(categories) AS category
+            WHERE filter_text_matches(match_type, pattern, category)
+        )
+        ELSE filter_text_matches(match_type, pattern, title)
+            OR filter_text_matches(match_type, pattern, description)
+            OR filter_text_matches(match_type, pattern, content)
+            OR filter_text_matches(match_type, pattern, author)
+            OR EXISTS (
+                SELECT 1 FROM unnest(categories) AS category
+                WHERE filter_text_matches(match_type, pattern, category)
+            )
+    END
+$$;
+-- +goose StatementEnd
+
+-- A post is hidden by any matching exclude rule. When include rules apply to its feed,
+-- it must also match one of them.
+-- +goose StatementBegin
+CREATE FUNCTION post_passes_filters(
+    filter_user_id uuid, post_feed_id INTEGER,
+    title VARCHAR, description VARCHAR, content VARCHAR, author VARCHAR, categories TEXT[]
+) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT NOT EXISTS (
+        SELECT 1 FROM filter_rules
+        WHERE filter_rules.user_id = filter_user_id
+        AND (filter_rules.feed_id IS NULL OR filter_rules.feed_id = post_feed_id)
+        AND filter_rules.action = 'exclude'
+        AND filter_rule_matches(filter_rules.field, filter_rules.match_type, filter_rules.pattern,
+            title, description, content, author, categories)
+    )
+    AND (
+        NOT EXISTS (
+            SELECT 1 FROM filter_rules
+            WHERE filter_rules.user_id = filter_user_id
+            AND (filter_rules.feed_id IS NULL OR filter_rules.feed_id = post_feed_id)
+            AND filter_rules.action = 'include'
+        )
+        OR EXISTS (
+            SELECT 1 FROM filter_rules
+            WHERE filter_rules.user_id = filter_user_id
+            AND (filter_rules.feed_id IS NULL OR filter_rules.feed_id = post_feed_id)
+            AND filter_rules.action = 'include'
+            AND filter_rule_matches(filter_rules.field, filter_rules.match_type, filter_rules.pattern,
+                title, description, content, author, categories)
+        )
+    )
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION post_passes_filters;
+
+DROP FUNCTION filter_rule_matches;
+
+DROP FUNCTION filter_text_matches;
+
+DROP TABLE filter_rules;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- Folders replace the free text category of feed_follows. Nested folders are stored
+-- as paths (e.g. "dev/go"), like the nested outlines of OPML.
+CREATE TABLE folders (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id uuid NOT NULL,
+    name VARCHAR NOT NULL,
+    UNIQUE (user_id, name),
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+ALTER TABLE feed_follows
+ADD COLUMN folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL;
+
+INSERT INTO folders (created_at, user_id, name)
+SELECT DISTINCT NOW(), user_id, category
+FROM feed_follows
+WHERE category IS NOT NULL AND category <> '';
+
+UPDATE feed_follows
+SET folder_id = folders.id
+FROM folders
+WHERE folders.user_id = feed_follows.user_id AND folders.name = feed_follows.category;
+
+ALTER TABLE feed_follows
+DROP COLUMN category;
+
+-- +goose Down
+ALTER TABLE feed_follows
+ADD COLUMN category VARCHAR;
+
+UPDATE feed_follows
+SET category = folders.name
+FROM folders
+WHERE folders.id = feed_follows.folder_id;
+
+ALTER TABLE feed_follows
+DROP COLUMN folder_id;
+
+DROP TABLE folders;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Name of the feed for this follower only, NULL shows the name of the feed
+ALTER TABLE feed_follows
+ADD COLUMN title VARCHAR;
+
+-- +goose Down
+ALTER TABLE feed_follows
+DROP COLUMN title;
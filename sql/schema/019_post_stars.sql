@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE post_stars (
+    user_id uuid NOT NULL,
+    post_id INTEGER NOT NULL,
+    starred_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id),
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX post_stars_post_id_idx ON post_stars (post_id);
+
+-- +goose Down
+DROP TABLE post_stars;
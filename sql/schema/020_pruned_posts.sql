@@ -0,0 +1,15 @@
+-- +goose Up
+-- Keys of the posts removed by prune, so they are not inserted again while still in their feed.
+-- They are forgotten once their feed stops listing them (see ForgetPrunedPosts).
+CREATE TABLE pruned_posts (
+    feed_id INTEGER NOT NULL,
+    dedup_key VARCHAR NOT NULL,
+    pruned_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (feed_id, dedup_key),
+    FOREIGN KEY (feed_id)
+    REFERENCES feed(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE pruned_posts;
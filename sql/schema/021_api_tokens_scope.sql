@@ -0,0 +1,8 @@
+-- +goose Up
+-- api tokens authenticate API calls, publish tokens are the secret in the url of a user's published feed
+ALTER TABLE api_tokens
+ADD COLUMN scope VARCHAR NOT NULL DEFAULT 'api';
+
+-- +goose Down
+ALTER TABLE api_tokens
+DROP COLUMN scope;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Revisions keep every field of the content hash, so diff shows all of what changed.
+-- The existing ones predate this and get the fields of their post, as if they didn't change.
+ALTER TABLE post_revisions
+ADD COLUMN author VARCHAR NOT NULL DEFAULT '';
+
+ALTER TABLE post_revisions
+ADD COLUMN categories TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE post_revisions
+ADD COLUMN image_url VARCHAR NOT NULL DEFAULT '';
+
+UPDATE post_revisions
+SET author = posts.author, categories = posts.categories, image_url = posts.image_url
+FROM posts
+WHERE posts.id = post_revisions.post_id;
+
+-- +goose Down
+ALTER TABLE post_revisions
+DROP COLUMN image_url;
+
+ALTER TABLE post_revisions
+DROP COLUMN categories;
+
+ALTER TABLE post_revisions
+DROP COLUMN author;